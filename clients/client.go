@@ -0,0 +1,194 @@
+package clients
+
+import (
+	"context"
+	"server/commands"
+	"server/errs"
+	"server/store"
+	"server/store/pubsub"
+	"sync"
+	"sync/atomic"
+)
+
+// Client is the state of a connection shared by the commands it runs
+type Client struct {
+	ID int64
+
+	// the database selected with SELECT, only used by the connection itself
+	db int
+
+	// the transaction started with MULTI, only used by the connection itself
+	multi     bool
+	queued    []*commands.RedisCommand
+	aborted   bool // a command could not be queued, EXEC discards the transaction
+	executing bool // EXEC is running the queued commands
+
+	// the keys watched with WATCH, guarded by the store lock
+	watch *store.Watch
+
+	// the channels subscribed to, the connection is in subscribed mode while there are any
+	subscriber *pubsub.Subscriber
+
+	// done once the connection is closed
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+
+	mu      sync.Mutex
+	unblock context.CancelCauseFunc // set while a blocking command runs
+}
+
+var (
+	lastID atomic.Int64
+
+	mu       sync.Mutex
+	registry = make(map[int64]*Client)
+)
+
+func NewClient() *Client {
+	ctx, cancel := context.WithCancelCause(context.Background())
+
+	client := &Client{
+		ID:     lastID.Add(1),
+		ctx:    ctx,
+		cancel: cancel,
+		watch:      store.NewWatch(),
+		subscriber: pubsub.NewSubscriber(),
+	}
+
+	mu.Lock()
+	registry[client.ID] = client
+	mu.Unlock()
+
+	return client
+}
+
+// Get looks up a connected client by its ID
+func Get(id int64) (*Client, bool) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	client, ok := registry[id]
+	return client, ok
+}
+
+// Close ends the client, a blocked command it is running gives up waiting
+func (client *Client) Close() {
+	client.cancel(errs.ConnectionClosed)
+
+	mu.Lock()
+	delete(registry, client.ID)
+	mu.Unlock()
+}
+
+func (client *Client) DB() int {
+	return client.db
+}
+
+func (client *Client) Select(db int) {
+	client.db = db
+}
+
+func (client *Client) Context() context.Context {
+	return client.ctx
+}
+
+// Block returns the context a blocking command waits on. It is done when the connection
+// closes or CLIENT UNBLOCK is called, with the reason as its cause. release must be called
+// once the command stops waiting.
+//
+// The commands EXEC runs do not wait, their context is done already with errs.Timeout as its cause.
+func (client *Client) Block() (context.Context, func()) {
+	if client.executing {
+		ctx, cancel := context.WithCancelCause(client.ctx)
+		cancel(errs.Timeout)
+
+		return ctx, func() {}
+	}
+
+	ctx, cancel := context.WithCancelCause(client.ctx)
+
+	client.mu.Lock()
+	client.unblock = cancel
+	client.mu.Unlock()
+
+	release := func() {
+		client.mu.Lock()
+		client.unblock = nil
+		client.mu.Unlock()
+
+		cancel(nil)
+	}
+
+	return ctx, release
+}
+
+// Unblock wakes the blocking command of the client with cause, reporting whether one was running
+func (client *Client) Unblock(cause error) bool {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	if client.unblock == nil {
+		return false
+	}
+
+	client.unblock(cause)
+	client.unblock = nil
+
+	return true
+}
+
+// ——— Transactions ———
+
+// Multi starts queueing the commands of the client, false when a transaction is already started
+func (client *Client) Multi() bool {
+	if client.multi {
+		return false
+	}
+
+	client.multi = true
+	return true
+}
+
+func (client *Client) InTransaction() bool {
+	return client.multi
+}
+
+func (client *Client) Queue(cmd *commands.RedisCommand) {
+	client.queued = append(client.queued, cmd)
+}
+
+// AbortTransaction marks the transaction of the client to be discarded by EXEC,
+// it is called when a command can not be queued
+func (client *Client) AbortTransaction() {
+	if client.multi {
+		client.aborted = true
+	}
+}
+
+// EndTransaction leaves the transaction and returns its queued commands,
+// false when it was aborted
+func (client *Client) EndTransaction() ([]*commands.RedisCommand, bool) {
+	queued, aborted := client.queued, client.aborted
+
+	client.multi = false
+	client.queued = nil
+	client.aborted = false
+
+	return queued, !aborted
+}
+
+func (client *Client) Watch() *store.Watch {
+	return client.watch
+}
+
+func (client *Client) Subscriber() *pubsub.Subscriber {
+	return client.subscriber
+}
+
+// Exec runs the queued commands of a transaction with run, blocking commands do not wait meanwhile
+func (client *Client) Exec(run func()) {
+	client.executing = true
+	defer func() { client.executing = false }()
+
+	run()
+}
@@ -0,0 +1,46 @@
+package cluster
+
+// SLOTS is the number of hash slots keys and shard channels are spread over
+const SLOTS = 16384
+
+// KeySlot returns the hash slot of a key or shard channel, the CRC16 of the key modulo SLOTS.
+// When the key has a non-empty hash tag, the part between the first { and the next },
+// only the tag is hashed so that related keys land in the same slot.
+func KeySlot(key string) int {
+	for i := 0; i < len(key); i++ {
+		if key[i] != '{' {
+			continue
+		}
+
+		for j := i + 1; j < len(key); j++ {
+			if key[j] == '}' {
+				if j > i+1 {
+					key = key[i+1 : j]
+				}
+				break
+			}
+		}
+		break
+	}
+
+	return int(crc16(key)) % SLOTS
+}
+
+// crc16 is the CRC16-CCITT (XMODEM) checksum used by redis cluster
+func crc16(s string) uint16 {
+	crc := uint16(0)
+
+	for i := 0; i < len(s); i++ {
+		crc ^= uint16(s[i]) << 8
+
+		for range 8 {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+
+	return crc
+}
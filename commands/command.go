@@ -5,4 +5,17 @@ import "server/store/actions"
 type RedisCommand struct {
 	Action    actions.Action
 	Arguments []string
+
+	DB int // the database the command ran against, the AOF selects it before the command
+
+	// the mutations an EXEC ran, the AOF writes them wrapped in MULTI/EXEC
+	Transaction []*RedisCommand
+
+	// the pops of the blocked clients the command served, the AOF writes them right after
+	// the command so that a replay pops what was pushed. It also holds what a served client
+	// that left gave back.
+	Served []*RedisCommand
+
+	// a blocking command served by another command, which logged it
+	Logged bool
 }
@@ -0,0 +1,57 @@
+package executor
+
+import (
+	"server/clients"
+	"server/commands"
+	"server/errs"
+	"strconv"
+	"strings"
+)
+
+// ———————————————————————————————————————————————————————————————
+// Connection commands
+// ———————————————————————————————————————————————————————————————
+
+// CLIENT ID, CLIENT UNBLOCK client-id [TIMEOUT|ERROR]
+func (e *Executor) executeClientCommand(cmd *commands.RedisCommand, client *clients.Client) []byte {
+	switch strings.ToUpper(cmd.Arguments[0]) {
+	case "ID":
+		if len(cmd.Arguments) != 1 {
+			return e.sr.GetErrorBytes(errs.IncorrectNumberOfArguments.Error())
+		}
+
+		return e.sr.GetIntegerBytes(int(client.ID))
+
+	case "UNBLOCK":
+		if len(cmd.Arguments) != 2 && len(cmd.Arguments) != 3 {
+			return e.sr.GetErrorBytes(errs.IncorrectNumberOfArguments.Error())
+		}
+
+		id, err := strconv.ParseInt(cmd.Arguments[1], 10, 64)
+		if err != nil {
+			return e.sr.GetErrorBytes(errs.NotAnInteger.Error())
+		}
+
+		// TIMEOUT makes the command reply as if its timeout passed, ERROR fails it
+		cause := errs.Timeout
+		if len(cmd.Arguments) == 3 {
+			switch strings.ToUpper(cmd.Arguments[2]) {
+			case "TIMEOUT":
+			case "ERROR":
+				cause = errs.Unblocked
+			default:
+				return e.sr.GetErrorBytes("CLIENT UNBLOCK REASON SHOULD BE TIMEOUT OR ERROR")
+			}
+		}
+
+		target, ok := clients.Get(id)
+		if !ok || !target.Unblock(cause) {
+			return e.sr.GetIntegerBytes(0)
+		}
+
+		return e.sr.GetIntegerBytes(1)
+
+	default:
+		return e.sr.GetErrorBytes(errs.SyntaxError.Error())
+	}
+}
@@ -0,0 +1,64 @@
+package executor
+
+import (
+	"fmt"
+	"server/commands"
+	"server/errs"
+	"server/glob"
+	"server/store/pubsub"
+	"strings"
+)
+
+// ———————————————————————————————————————————————————————————————
+// Server configuration
+// ———————————————————————————————————————————————————————————————
+
+// the only parameter CONFIG knows about, the event classes published as keyspace notifications
+const NOTIFY_KEYSPACE_EVENTS = "notify-keyspace-events"
+
+// CONFIG GET parameter [parameter ...], CONFIG SET parameter value [parameter value ...]
+func (e *Executor) executeConfigCommand(cmd *commands.RedisCommand) []byte {
+	switch strings.ToUpper(cmd.Arguments[0]) {
+	case "GET":
+		patterns := cmd.Arguments[1:]
+		if len(patterns) == 0 {
+			return e.sr.GetErrorBytes(errs.IncorrectNumberOfArguments.Error())
+		}
+
+		for _, pattern := range patterns {
+			if glob.Match(strings.ToLower(pattern), NOTIFY_KEYSPACE_EVENTS) {
+				return e.sr.GetArrayOfBulkStringBytes([]string{NOTIFY_KEYSPACE_EVENTS, e.notifier.Classes().String()})
+			}
+		}
+
+		return e.sr.GetArrayOfBulkStringBytes([]string{})
+
+	case "SET":
+		pairs := cmd.Arguments[1:]
+		if len(pairs) == 0 || len(pairs)&1 == 1 {
+			return e.sr.GetErrorBytes(errs.IncorrectNumberOfArguments.Error())
+		}
+
+		// every value is checked before any is applied
+		classes := e.notifier.Classes()
+		for i := 0; i < len(pairs); i += 2 {
+			parameter, value := strings.ToLower(pairs[i]), pairs[i+1]
+
+			if parameter != NOTIFY_KEYSPACE_EVENTS {
+				return e.sr.GetErrorBytes(fmt.Sprintf("UNKNOWN OPTION OR NUMBER OF ARGUMENTS FOR CONFIG SET - '%s'", pairs[i]))
+			}
+
+			parsed, ok := pubsub.ParseEventClasses(value)
+			if !ok {
+				return e.sr.GetErrorBytes(fmt.Sprintf("INVALID ARGUMENT '%s' FOR CONFIG SET '%s'", value, parameter))
+			}
+			classes = parsed
+		}
+
+		e.notifier.SetClasses(classes)
+		return e.sr.GetSimpleStringBytes("OK")
+
+	default:
+		return e.sr.GetErrorBytes(errs.SyntaxError.Error())
+	}
+}
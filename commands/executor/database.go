@@ -0,0 +1,91 @@
+package executor
+
+import (
+	"server/clients"
+	"server/commands"
+	"server/errs"
+	"server/store"
+	"server/store/actions"
+	"strconv"
+	"strings"
+)
+
+// ———————————————————————————————————————————————————————————————
+// Database commands
+// ———————————————————————————————————————————————————————————————
+
+func (e *Executor) parseDatabaseIndex(s string) (*store.Store, error) {
+	index, err := strconv.Atoi(s)
+	if err != nil {
+		return nil, errs.NotAnInteger
+	}
+
+	if index < 0 || index >= len(e.databases) {
+		return nil, errs.DBIndexOutOfRange
+	}
+
+	return e.databases[index], nil
+}
+
+func (e *Executor) executeDatabaseCommand(cmd *commands.RedisCommand, client *clients.Client) []byte {
+	switch cmd.Action {
+	case actions.Select:
+		db, err := e.parseDatabaseIndex(cmd.Arguments[0])
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		client.Select(db.Index())
+		return e.sr.GetSimpleStringBytes("OK")
+
+	case actions.Move:
+		db, err := e.parseDatabaseIndex(cmd.Arguments[1])
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		if db == e.store {
+			return e.sr.GetErrorBytes(errs.SameObject.Error())
+		}
+
+		if e.store.Move(cmd.Arguments[0], db) {
+			return e.sr.GetIntegerBytes(1)
+		}
+		return e.sr.GetIntegerBytes(0)
+
+	case actions.SwapDB:
+		a, err := e.parseDatabaseIndex(cmd.Arguments[0])
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		b, err := e.parseDatabaseIndex(cmd.Arguments[1])
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		store.SwapDatabases(a.LogServedTo(&cmd.Served), b)
+		return e.sr.GetSimpleStringBytes("OK")
+
+	// FLUSHDB [ASYNC|SYNC], ASYNC is accepted but the dropped keys are always
+	// left to the garbage collector
+	case actions.FlushDB, actions.FlushAll:
+		if len(cmd.Arguments) == 1 {
+			mode := strings.ToUpper(cmd.Arguments[0])
+			if mode != "ASYNC" && mode != "SYNC" {
+				return e.sr.GetErrorBytes(errs.SyntaxError.Error())
+			}
+		}
+
+		if cmd.Action == actions.FlushDB {
+			e.store.FlushDB()
+		} else {
+			store.FlushAll(e.databases)
+		}
+
+		return e.sr.GetSimpleStringBytes("OK")
+
+	default:
+		return e.sr.GetErrorBytes("ERR unknown command")
+	}
+}
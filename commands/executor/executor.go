@@ -3,26 +3,54 @@ package executor
 import (
 	"errors"
 	"fmt"
+	"math"
+	"server/clients"
 	"server/commands"
 	"server/commands/serializer"
 	"server/errs"
 	"server/store"
 	"server/store/actions"
+	"server/store/pubsub"
 	"strconv"
+	"time"
 )
 
 type Executor struct {
-	store *store.Store
+	store *store.Store // the database of the command being executed
+	databases []*store.Store
+	hub *pubsub.Hub
+	notifier *pubsub.Notifier
 	sr *serializer.Serializer
 }
 
-func NewExecutor(store *store.Store) *Executor {
+func NewExecutor(databases []*store.Store, hub *pubsub.Hub, notifier *pubsub.Notifier) *Executor {
 	return &Executor{
-		store: store,
+		store: databases[0],
+		databases: databases,
+		hub: hub,
+		notifier: notifier,
 		sr: serializer.NewSerializer(),
 	}
 }
 
+// forCommand returns an executor running cmd against the database it selected, the pops of
+// the blocked clients cmd serves are kept on it for the AOF
+func (e *Executor) forCommand(cmd *commands.RedisCommand) *Executor {
+	return &Executor{
+		store: e.databases[cmd.DB].LogServedTo(&cmd.Served),
+		databases: e.databases,
+		hub: e.hub,
+		notifier: e.notifier,
+		sr: e.sr,
+	}
+}
+
+// Disconnect drops what a closed connection left in the databases
+func (e *Executor) Disconnect(client *clients.Client) {
+	store.Unwatch(e.databases, client.Watch())
+	e.hub.UnsubscribeAll(client.Subscriber())
+}
+
 func (e *Executor) ParseCommand(msg any) (*commands.RedisCommand, error) {
 	switch msg := msg.(type) {
 
@@ -73,20 +101,63 @@ func (e *Executor) ParseCommand(msg any) (*commands.RedisCommand, error) {
 	}
 }
 
-func (e *Executor) ExecuteCommand(cmd *commands.RedisCommand) []byte {
+func (e *Executor) ExecuteCommand(cmd *commands.RedisCommand, client *clients.Client) []byte {
 	err := e.validateCommandArgs(cmd)
 
 	if err != nil {
+		client.AbortTransaction()
 		return e.sr.GetErrorBytes(err.Error())
 	}
 
+	if client.InTransaction() && !isTransactionCommand(cmd.Action) {
+		if _, ok := actions.SubscribedModeCommands[cmd.Action]; ok && cmd.Action != actions.Ping {
+			client.AbortTransaction()
+			return e.sr.GetErrorBytes("COMMAND NOT ALLOWED INSIDE A TRANSACTION")
+		}
+
+		client.Queue(cmd)
+		return e.sr.GetSimpleStringBytes("QUEUED")
+	}
+
+	cmd.DB = client.DB()
+	e = e.forCommand(cmd)
+
 	switch cmd.Action {
 	case actions.Ping:
+		// a subscribed connection gets a frame it can tell apart from the messages
+		if client.Subscriber().Subscribed() {
+			return e.sr.GetArrayOfBulkStringBytes([]string{"pong", ""})
+		}
 		return e.sr.GetSimpleStringBytes("PONG")
 
+	case actions.Quit:
+		return e.sr.GetSimpleStringBytes("OK")
+
+	case actions.Subscribe, actions.Unsubscribe, actions.PSubscribe, actions.PUnsubscribe, actions.SSubscribe, actions.SUnsubscribe,
+		actions.Publish, actions.SPublish, actions.PubSub:
+		return e.executePubSubCommand(cmd, client)
+
 	case actions.Echo:
 		return e.sr.GetSimpleStringBytes(cmd.Arguments[0])
 
+	case actions.Client:
+		return e.executeClientCommand(cmd, client)
+
+	case actions.Config:
+		return e.executeConfigCommand(cmd)
+
+	case actions.Scan, actions.HScan, actions.SScan, actions.ZScan:
+		return e.executeScanCommand(cmd)
+
+	case actions.Keys, actions.Type, actions.Rename, actions.RenameNX, actions.RandomKey, actions.DBSize, actions.Touch, actions.Copy:
+		return e.executeKeyspaceCommand(cmd)
+
+	case actions.Select, actions.Move, actions.SwapDB, actions.FlushDB, actions.FlushAll:
+		return e.executeDatabaseCommand(cmd, client)
+
+	case actions.Multi, actions.Exec, actions.Discard, actions.Watch, actions.Unwatch:
+		return e.executeTransactionCommand(cmd, client)
+
 	case actions.Get:
 		value, err := e.store.Get(cmd.Arguments[0])
 		if err == errs.ErrNotFound {
@@ -99,8 +170,7 @@ func (e *Executor) ExecuteCommand(cmd *commands.RedisCommand) []byte {
 		return e.sr.GetSimpleStringBytes(value)
 
 	case actions.Set:
-		e.store.Set(cmd.Arguments[0], cmd.Arguments[1])
-		return e.sr.GetSimpleStringBytes("OK")
+		return e.set(cmd)
 
 	case actions.Del:
 		n := e.store.Delete(cmd.Arguments)
@@ -112,22 +182,14 @@ func (e *Executor) ExecuteCommand(cmd *commands.RedisCommand) []byte {
 		response := fmt.Sprintf("%d", n)
 		return e.sr.GetSimpleStringBytes(response)
 
-	case actions.Expire:
-		expiry_seconds, err := strconv.Atoi(cmd.Arguments[1])
-		if err != nil || expiry_seconds <= 0{
-			return e.sr.GetErrorBytes("TIME IS NOT A POSITIVE INTEGER")
-		}
+	case actions.Expire, actions.PExpire, actions.ExpireAt, actions.PExpireAt, actions.TTL, actions.PTTL,
+		actions.Persist, actions.ExpireTime, actions.PExpireTime:
+		return e.executeExpireCommand(cmd)
 
-		err = e.store.Expire(cmd.Arguments[0], int64(expiry_seconds))
-		if err != nil {
-			return e.sr.GetIntegerBytes(0)
-		}
-
-		return e.sr.GetIntegerBytes(1)
-	
-	case actions.TTL:
-		ttl := e.store.TTL(cmd.Arguments[0])
-		return e.sr.GetIntegerBytes(ttl)
+	case actions.Incr, actions.Decr, actions.IncrBy, actions.DecrBy, actions.IncrByFloat,
+		actions.Append, actions.StrLen, actions.GetRange, actions.SetRange,
+		actions.MGet, actions.MSet, actions.MSetNX, actions.GetDel, actions.GetEx, actions.GetSet:
+		return e.executeStringCommand(cmd)
 
 	case actions.LPush:
 		n, err := e.store.LPush(cmd.Arguments[0], cmd.Arguments[1:])
@@ -175,19 +237,10 @@ func (e *Executor) ExecuteCommand(cmd *commands.RedisCommand) []byte {
 		}
 		return e.sr.GetArrayOfBulkStringBytes(items)
 
-	case actions.BLPop:
-		item, err := e.store.BLPop(cmd.Arguments[0])
-		if err != nil {
-			return e.sr.GetErrorBytes(err.Error())
-		}
-		return e.sr.GetBulkStringBytes(item)
-
-	case actions.BRPop:
-		item, err := e.store.BRPop(cmd.Arguments[0])
-		if err != nil {
-			return e.sr.GetErrorBytes(err.Error())
-		}
-		return e.sr.GetBulkStringBytes(item)
+	case actions.LRange, actions.LIndex, actions.LSet, actions.LInsert, actions.LRem, actions.LTrim, actions.LLen, actions.LPos,
+		actions.LMove, actions.BLMove, actions.RPopLPush, actions.BRPopLPush,
+		actions.BLPop, actions.BRPop, actions.LMPop, actions.BLMPop:
+		return e.executeListCommand(cmd, client)
 
 
 	// ———————————————————————————————————————————————————————————————
@@ -241,6 +294,26 @@ func (e *Executor) ExecuteCommand(cmd *commands.RedisCommand) []byte {
 
 		return e.sr.GetIntegerBytes(cnt)
 
+	case actions.HLen, actions.HKeys, actions.HVals, actions.HExists, actions.HSetNX, actions.HMGet,
+		actions.HIncrBy, actions.HIncrByFloat, actions.HStrLen, actions.HRandField,
+		actions.HExpire, actions.HPExpire, actions.HExpireAt, actions.HPExpireAt, actions.HTTL, actions.HPTTL, actions.HPersist:
+		return e.executeHashCommand(cmd)
+
+	case actions.SAdd, actions.SRem, actions.SMembers, actions.SIsMember, actions.SCard,
+		actions.SInter, actions.SUnion, actions.SDiff, actions.SInterStore, actions.SUnionStore, actions.SDiffStore:
+		return e.executeSetCommand(cmd)
+
+	case actions.ZAdd, actions.ZRange, actions.ZRank, actions.ZRevRank, actions.ZScore, actions.ZIncrBy, actions.ZRem, actions.ZCard,
+		actions.ZPopMin, actions.ZPopMax, actions.BZPopMin, actions.BZPopMax,
+		actions.ZRemRangeByScore, actions.ZRemRangeByRank, actions.ZRemRangeByLex:
+		return e.executeSortedSetCommand(cmd, client)
+
+	case actions.XAdd, actions.XRange, actions.XRevRange, actions.XLen, actions.XDel, actions.XTrim, actions.XRead:
+		return e.executeStreamCommand(cmd, client)
+
+	case actions.XGroup, actions.XReadGroup, actions.XAck, actions.XPending, actions.XClaim, actions.XAutoClaim:
+		return e.executeStreamGroupCommand(cmd, client)
+
 	default:
 		return e.sr.GetErrorBytes("ERR unknown command")
 	}
@@ -259,44 +332,115 @@ func (e *Executor) validateCommandExistence(command string) (actions.Action, err
 
 func (e *Executor) validateCommandArgs(cmd *commands.RedisCommand) error {
 	switch cmd.Action {
-	case actions.Ping:
+	case actions.Ping, actions.Quit, actions.RandomKey, actions.DBSize, actions.Multi, actions.Exec, actions.Discard, actions.Unwatch:
 		if len(cmd.Arguments) != 0 {
 			return errs.IncorrectNumberOfArguments
 		}
 
 		return nil
 
-	// GET key, hgetall key
-	case actions.Get, actions.Echo, actions.TTL, actions.HGetAll:
+	// select index, flushdb [ASYNC|SYNC]
+	case actions.Select:
+		if len(cmd.Arguments) != 1 {
+			return errs.IncorrectNumberOfArguments
+		}
+
+		return nil
+
+	case actions.FlushDB, actions.FlushAll:
+		if len(cmd.Arguments) > 1 {
+			return errs.IncorrectNumberOfArguments
+		}
+
+		return nil
+
+	// GET key, hgetall key, smembers key, scard key, zcard key, xlen key, incr key
+	case actions.Get, actions.Echo, actions.TTL, actions.PTTL, actions.Persist, actions.ExpireTime, actions.PExpireTime, actions.HGetAll, actions.SMembers, actions.SCard, actions.ZCard, actions.XLen,
+		actions.Incr, actions.Decr, actions.StrLen, actions.GetDel, actions.LLen,
+		actions.HLen, actions.HKeys, actions.HVals, actions.Keys, actions.Type:
 		if len(cmd.Arguments) != 1 {
 			return errs.IncorrectNumberOfArguments
 		}
 
 		return nil
 
-	case actions.Del, actions.Exists:
+	// sinter key [key ...], mget key [key ...], getex key [options], client subcommand [...]
+	// scan cursor [options], touch key [key ...], watch key [key ...], config subcommand [...]
+	// subscribe channel [channel ...], psubscribe pattern [pattern ...], pubsub subcommand [...]
+	case actions.Scan, actions.Touch, actions.Watch, actions.Subscribe, actions.PSubscribe, actions.SSubscribe, actions.PubSub, actions.Client, actions.Config, actions.Del, actions.Exists, actions.SInter, actions.SUnion, actions.SDiff, actions.MGet, actions.GetEx:
 		if len(cmd.Arguments) < 1 {
 			return errs.IncorrectNumberOfArguments
 		}
 
 		return nil
 
-	// hdel key field [field...]
-	case actions.HDel:
+	// hdel key field [field...], hmget key field [field ...], sadd key member [member...], srem key member [member...]
+	// sinterstore destination key [key ...], hscan key cursor [options]
+	// zrem key member [member ...], xdel key id [id ...]
+	case actions.HScan, actions.SScan, actions.ZScan, actions.HDel, actions.HMGet, actions.SAdd, actions.SRem, actions.SInterStore, actions.SUnionStore, actions.SDiffStore, actions.ZRem, actions.XDel:
 		if len(cmd.Arguments) < 2 {
 			return errs.IncorrectNumberOfArguments
 		}
 		return nil
 	
-	// set key value expire key seconds, hget key field
-	case actions.Set, actions.Expire, actions.HGet:
+	// hget key field, hexists key field, sismember key member, zscore key member
+	// incrby key increment, append key value, rename key newkey, move key db, swapdb index1 index2
+	// publish channel message
+	case actions.Publish, actions.SPublish, actions.Move, actions.SwapDB, actions.Rename, actions.RenameNX, actions.HGet, actions.HExists, actions.HStrLen, actions.SIsMember, actions.ZScore, actions.ZRank, actions.ZRevRank,
+		actions.IncrBy, actions.DecrBy, actions.IncrByFloat, actions.Append, actions.GetSet, actions.LIndex, actions.RPopLPush:
 		if len(cmd.Arguments) != 2 {
 			return errs.IncorrectNumberOfArguments
 		}
 		return nil
 
+	// unsubscribe [channel ...], punsubscribe [pattern ...]
+	case actions.Unsubscribe, actions.PUnsubscribe, actions.SUnsubscribe:
+		return nil
+
+	// expire key seconds [NX|XX|GT|LT]
+	case actions.Expire, actions.PExpire, actions.ExpireAt, actions.PExpireAt:
+		if len(cmd.Arguments) != 2 && len(cmd.Arguments) != 3 {
+			return errs.IncorrectNumberOfArguments
+		}
+		return nil
+
+	// set key value [NX|XX] [GET] [EX|PX|EXAT|PXAT time|KEEPTTL]
+	case actions.Set:
+		if len(cmd.Arguments) < 2 {
+			return errs.IncorrectNumberOfArguments
+		}
+		return nil
+
+	// copy source destination [DB destination-db] [REPLACE]
+	case actions.Copy:
+		if len(cmd.Arguments) < 2 {
+			return errs.IncorrectNumberOfArguments
+		}
+		return nil
+
+	// linsert key BEFORE|AFTER pivot element, lmove source destination LEFT|RIGHT LEFT|RIGHT
+	case actions.LInsert, actions.LMove:
+		if len(cmd.Arguments) != 4 {
+			return errs.IncorrectNumberOfArguments
+		}
+		return nil
+
+	// blmove source destination LEFT|RIGHT LEFT|RIGHT timeout
+	case actions.BLMove:
+		if len(cmd.Arguments) != 5 {
+			return errs.IncorrectNumberOfArguments
+		}
+		return nil
+
+	// lpos key element [RANK rank] [COUNT num-matches] [MAXLEN len]
+	case actions.LPos:
+		if len(cmd.Arguments) < 2 {
+			return errs.IncorrectNumberOfArguments
+		}
+		return nil
+
 	// list, count or list
-	case actions.LPop, actions.RPop:
+	case actions.LPop, actions.RPop, actions.ZPopMin, actions.ZPopMax:
 		if len(cmd.Arguments) != 1 && len(cmd.Arguments) != 2 {
 			return errs.IncorrectNumberOfArguments
 		}
@@ -308,12 +452,105 @@ func (e *Executor) validateCommandArgs(cmd *commands.RedisCommand) error {
 		}
 		return nil
 	
+	// blpop key [key ...] timeout
 	case actions.BLPop, actions.BRPop:
-		if len(cmd.Arguments) != 1 {
+		if len(cmd.Arguments) < 2 {
+			return errs.IncorrectNumberOfArguments
+		}
+		return nil
+
+	// lmpop numkeys key [key ...] LEFT|RIGHT [COUNT count], blmpop timeout numkeys [...]
+	case actions.LMPop:
+		if len(cmd.Arguments) < 3 {
+			return errs.IncorrectNumberOfArguments
+		}
+		return nil
+
+	case actions.BLMPop:
+		if len(cmd.Arguments) < 4 {
 			return errs.IncorrectNumberOfArguments
 		}
 		return nil
 	
+	// zadd key [options] score member [...], zrange key start stop [options]
+	case actions.ZAdd, actions.ZRange:
+		if len(cmd.Arguments) < 3 {
+			return errs.IncorrectNumberOfArguments
+		}
+		return nil
+
+	// zincrby key increment member, zremrangebyscore key min max, getrange key start end
+	// lrange key start stop, lset key index element, lrem key count element
+	// brpoplpush source destination timeout, hsetnx key field value, hincrby key field increment
+	case actions.HSetNX, actions.HIncrBy, actions.HIncrByFloat, actions.LRange, actions.LSet, actions.LRem, actions.LTrim, actions.BRPopLPush, actions.GetRange, actions.SetRange, actions.ZIncrBy, actions.ZRemRangeByScore, actions.ZRemRangeByRank, actions.ZRemRangeByLex:
+		if len(cmd.Arguments) != 3 {
+			return errs.IncorrectNumberOfArguments
+		}
+		return nil
+
+	// xadd key [options] id field value [...], xtrim key strategy threshold [...]
+	// xrange key start end [COUNT count], xread [options] STREAMS key id [...]
+	case actions.XAdd:
+		if len(cmd.Arguments) < 4 {
+			return errs.IncorrectNumberOfArguments
+		}
+		return nil
+
+	case actions.XTrim, actions.XRange, actions.XRevRange, actions.XRead, actions.XAck:
+		if len(cmd.Arguments) < 3 {
+			return errs.IncorrectNumberOfArguments
+		}
+		return nil
+
+	// xgroup subcommand key group [...], xpending key group [...]
+	case actions.XGroup, actions.XPending:
+		if len(cmd.Arguments) < 2 {
+			return errs.IncorrectNumberOfArguments
+		}
+		return nil
+
+	// xautoclaim key group consumer min-idle start [...], xclaim key group consumer min-idle id [...]
+	// xreadgroup GROUP group consumer [...] STREAMS key id
+	case actions.XAutoClaim, actions.XClaim, actions.XReadGroup:
+		if len(cmd.Arguments) < 5 {
+			return errs.IncorrectNumberOfArguments
+		}
+		return nil
+
+	// bzpopmin key [key ...] timeout
+	case actions.BZPopMin, actions.BZPopMax:
+		if len(cmd.Arguments) < 2 {
+			return errs.IncorrectNumberOfArguments
+		}
+		return nil
+
+	// mset key value [key value ...]
+	case actions.MSet, actions.MSetNX:
+		if len(cmd.Arguments) < 2 || len(cmd.Arguments) & 1 == 1 {
+			return errs.IncorrectNumberOfArguments
+		}
+		return nil
+
+	// hexpire key seconds [NX|XX|GT|LT] FIELDS numfields field [...], httl key FIELDS numfields field [...]
+	case actions.HExpire, actions.HPExpire, actions.HExpireAt, actions.HPExpireAt:
+		if len(cmd.Arguments) < 5 {
+			return errs.IncorrectNumberOfArguments
+		}
+		return nil
+
+	case actions.HTTL, actions.HPTTL, actions.HPersist:
+		if len(cmd.Arguments) < 4 {
+			return errs.IncorrectNumberOfArguments
+		}
+		return nil
+
+	// hrandfield key [count [WITHVALUES]]
+	case actions.HRandField:
+		if len(cmd.Arguments) < 1 || len(cmd.Arguments) > 3 {
+			return errs.IncorrectNumberOfArguments
+		}
+		return nil
+
 	// HSET key field value [field value ...]
 	case actions.HSet:
 		if len(cmd.Arguments) < 3 || len(cmd.Arguments) & 1 == 0 {
@@ -325,3 +562,17 @@ func (e *Executor) validateCommandArgs(cmd *commands.RedisCommand) error {
 		return errs.InvalidCommand
 	}
 }
+
+// parseTimeout reads a blocking timeout given in (possibly fractional) seconds
+func parseTimeout(s string) (time.Duration, error) {
+	seconds, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(seconds) || math.IsInf(seconds, 0) {
+		return 0, errors.New("TIMEOUT IS NOT A FLOAT OR OUT OF RANGE")
+	}
+
+	if seconds < 0 {
+		return 0, errors.New("TIMEOUT IS NEGATIVE")
+	}
+
+	return time.Duration(seconds * float64(time.Second)), nil
+}
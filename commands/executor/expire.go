@@ -0,0 +1,115 @@
+package executor
+
+import (
+	"fmt"
+	"math"
+	"server/commands"
+	"server/errs"
+	"server/store"
+	"server/store/actions"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ———————————————————————————————————————————————————————————————
+// Expiry commands
+// ———————————————————————————————————————————————————————————————
+
+func (e *Executor) executeExpireCommand(cmd *commands.RedisCommand) []byte {
+	switch cmd.Action {
+	case actions.Expire, actions.PExpire, actions.ExpireAt, actions.PExpireAt:
+		return e.expire(cmd)
+
+	case actions.TTL:
+		return e.sr.GetIntegerBytes(e.store.TTL(cmd.Arguments[0]))
+
+	case actions.PTTL:
+		return e.sr.GetIntegerBytes(int(e.store.PTTL(cmd.Arguments[0])))
+
+	case actions.Persist:
+		if e.store.Persist(cmd.Arguments[0]) {
+			return e.sr.GetIntegerBytes(1)
+		}
+		return e.sr.GetIntegerBytes(0)
+
+	case actions.ExpireTime, actions.PExpireTime:
+		at := e.store.ExpireTime(cmd.Arguments[0])
+		if at >= 0 && cmd.Action == actions.ExpireTime {
+			at /= 1000
+		}
+
+		return e.sr.GetIntegerBytes(int(at))
+
+	default:
+		return e.sr.GetErrorBytes("ERR unknown command")
+	}
+}
+
+// EXPIRE key seconds [NX|XX|GT|LT], also PEXPIRE, EXPIREAT and PEXPIREAT
+func (e *Executor) expire(cmd *commands.RedisCommand) []byte {
+	n, err := strconv.ParseInt(cmd.Arguments[1], 10, 64)
+	if err != nil {
+		return e.sr.GetErrorBytes(errs.NotAnInteger.Error())
+	}
+
+	condition := store.ExpireAlways
+	if len(cmd.Arguments) == 3 {
+		condition = store.ExpireCondition(strings.ToUpper(cmd.Arguments[2]))
+
+		switch condition {
+		case store.ExpireNX, store.ExpireXX, store.ExpireGT, store.ExpireLT:
+			;
+		default:
+			return e.sr.GetErrorBytes(errs.SyntaxError.Error())
+		}
+	}
+
+	ms, ok := expiryUnixMilli(cmd.Action, n)
+	if !ok {
+		return e.sr.GetErrorBytes(fmt.Sprintf("INVALID EXPIRE TIME IN '%s' COMMAND", strings.ToUpper(string(cmd.Action))))
+	}
+
+	applied, err := e.store.ExpireAt(cmd.Arguments[0], time.UnixMilli(ms), condition)
+	if err == errs.ErrNotFound {
+		return e.sr.GetIntegerBytes(0)
+	}
+
+	if err != nil {
+		return e.sr.GetErrorBytes(err.Error())
+	}
+
+	// logged as an absolute expiry so that an AOF replay keeps the deadline,
+	// the condition is kept since the replay sees the same state
+	cmd.Action = actions.PExpireAt
+	cmd.Arguments[1] = strconv.FormatInt(ms, 10)
+
+	if applied {
+		return e.sr.GetIntegerBytes(1)
+	}
+	return e.sr.GetIntegerBytes(0)
+}
+
+// expiryUnixMilli converts the time argument of the key and hash field expire commands to unix milliseconds,
+// reporting false when it overflows
+func expiryUnixMilli(action actions.Action, n int64) (int64, bool) {
+	base := int64(0)
+	switch action {
+	case actions.Expire, actions.PExpire, actions.HExpire, actions.HPExpire:
+		base = time.Now().UnixMilli()
+	}
+
+	switch action {
+	case actions.Expire, actions.ExpireAt, actions.HExpire, actions.HExpireAt:
+		if n > math.MaxInt64/1000 || n < math.MinInt64/1000 {
+			return 0, false
+		}
+		n *= 1000
+	}
+
+	if (n > 0 && base > math.MaxInt64-n) || (n < 0 && base < math.MinInt64-n) {
+		return 0, false
+	}
+
+	return base + n, true
+}
@@ -0,0 +1,320 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"server/commands"
+	"server/errs"
+	"server/store"
+	"server/store/actions"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ———————————————————————————————————————————————————————————————
+// Hash commands
+// ———————————————————————————————————————————————————————————————
+
+// MAX_RANDOM_FIELD_REPEATS bounds a negative HRANDFIELD count, every repeat is allocated under the lock
+const MAX_RANDOM_FIELD_REPEATS = 1 << 20
+
+// HRANDFIELD key [count [WITHVALUES]]
+func (e *Executor) hrandfield(cmd *commands.RedisCommand) []byte {
+	if len(cmd.Arguments) == 1 {
+		fields, _, err := e.store.HRandField(cmd.Arguments[0], 1)
+		if err == errs.ErrNotFound || (err == nil && len(fields) == 0) {
+			return e.sr.GetNil()
+		}
+
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		return e.sr.GetBulkStringBytes(fields[0])
+	}
+
+	count, err := strconv.Atoi(cmd.Arguments[1])
+	if err != nil {
+		return e.sr.GetErrorBytes(errs.NotAnInteger.Error())
+	}
+
+	// a positive count is capped by the hash size, a negative one is not
+	if count < -MAX_RANDOM_FIELD_REPEATS {
+		return e.sr.GetErrorBytes(errs.ValueOutOfRange.Error())
+	}
+
+	withValues := false
+	if len(cmd.Arguments) == 3 {
+		if strings.ToUpper(cmd.Arguments[2]) != "WITHVALUES" {
+			return e.sr.GetErrorBytes(errs.SyntaxError.Error())
+		}
+		withValues = true
+	}
+
+	fields, values, err := e.store.HRandField(cmd.Arguments[0], count)
+	if err == errs.ErrNotFound {
+		return e.sr.GetArrayOfBulkStringBytes([]string{})
+	}
+
+	if err != nil {
+		return e.sr.GetErrorBytes(err.Error())
+	}
+
+	if !withValues {
+		return e.sr.GetArrayOfBulkStringBytes(fields)
+	}
+
+	items := make([]string, 0, 2*len(fields))
+	for i := range fields {
+		items = append(items, fields[i], values[i])
+	}
+
+	return e.sr.GetArrayOfBulkStringBytes(items)
+}
+
+// HEXPIRE key seconds [NX|XX|GT|LT] FIELDS numfields field [field ...],
+// also HPEXPIRE, HEXPIREAT and HPEXPIREAT
+func (e *Executor) hexpire(cmd *commands.RedisCommand) []byte {
+	n, err := strconv.ParseInt(cmd.Arguments[1], 10, 64)
+	if err != nil {
+		return e.sr.GetErrorBytes(errs.NotAnInteger.Error())
+	}
+
+	condition := store.ExpireAlways
+	fieldsAt := 2
+
+	switch option := store.ExpireCondition(strings.ToUpper(cmd.Arguments[2])); option {
+	case store.ExpireNX, store.ExpireXX, store.ExpireGT, store.ExpireLT:
+		condition = option
+		fieldsAt++
+	}
+
+	fields, err := parseHashFields(cmd.Arguments[fieldsAt:])
+	if err != nil {
+		return e.sr.GetErrorBytes(err.Error())
+	}
+
+	ms, ok := expiryUnixMilli(cmd.Action, n)
+	if !ok {
+		return e.sr.GetErrorBytes(fmt.Sprintf("INVALID EXPIRE TIME IN '%s' COMMAND", strings.ToUpper(string(cmd.Action))))
+	}
+
+	results, err := e.store.HExpireAt(cmd.Arguments[0], time.UnixMilli(ms), condition, fields)
+	if err == errs.ErrNotFound {
+		return e.fieldCodesBytes(store.FieldNotFound, len(fields))
+	}
+
+	if err != nil {
+		return e.sr.GetErrorBytes(err.Error())
+	}
+
+	// logged as an absolute expiry, like EXPIRE
+	cmd.Action = actions.HPExpireAt
+	cmd.Arguments[1] = strconv.FormatInt(ms, 10)
+
+	return e.integerArrayBytes(results)
+}
+
+// parseHashFields reads FIELDS numfields field [field ...]
+func parseHashFields(args []string) ([]string, error) {
+	if len(args) < 2 || strings.ToUpper(args[0]) != "FIELDS" {
+		return nil, errs.SyntaxError
+	}
+
+	n, err := strconv.Atoi(args[1])
+	if err != nil || n <= 0 {
+		return nil, errors.New("NUMFIELDS MUST BE A POSITIVE INTEGER")
+	}
+
+	if n != len(args)-2 {
+		return nil, errors.New("NUMFIELDS DOES NOT MATCH THE NUMBER OF FIELDS")
+	}
+
+	return args[2:], nil
+}
+
+func (e *Executor) integerArrayBytes(values []int) []byte {
+	items := make([][]byte, len(values))
+	for i, value := range values {
+		items[i] = e.sr.GetIntegerBytes(value)
+	}
+
+	return e.sr.GetArrayBytes(items)
+}
+
+// fieldCodesBytes replies with the same per field code for every field
+func (e *Executor) fieldCodesBytes(code int, count int) []byte {
+	codes := make([]int, count)
+	for i := range codes {
+		codes[i] = code
+	}
+
+	return e.integerArrayBytes(codes)
+}
+
+func (e *Executor) executeHashCommand(cmd *commands.RedisCommand) []byte {
+	switch cmd.Action {
+	case actions.HLen:
+		length, err := e.store.HLen(cmd.Arguments[0])
+		if err == errs.ErrNotFound {
+			return e.sr.GetIntegerBytes(0)
+		}
+
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		return e.sr.GetIntegerBytes(length)
+
+	case actions.HKeys, actions.HVals:
+		var items []string
+		var err error
+
+		if cmd.Action == actions.HKeys {
+			items, err = e.store.HKeys(cmd.Arguments[0])
+		} else {
+			items, err = e.store.HVals(cmd.Arguments[0])
+		}
+
+		if err == errs.ErrNotFound {
+			return e.sr.GetArrayOfBulkStringBytes([]string{})
+		}
+
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		return e.sr.GetArrayOfBulkStringBytes(items)
+
+	case actions.HExists:
+		exists, err := e.store.HExists(cmd.Arguments[0], cmd.Arguments[1])
+		if err != nil && err != errs.ErrNotFound {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		if exists {
+			return e.sr.GetIntegerBytes(1)
+		}
+		return e.sr.GetIntegerBytes(0)
+
+	case actions.HSetNX:
+		written, err := e.store.HSetNX(cmd.Arguments[0], cmd.Arguments[1], cmd.Arguments[2])
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		if written {
+			return e.sr.GetIntegerBytes(1)
+		}
+		return e.sr.GetIntegerBytes(0)
+
+	case actions.HMGet:
+		values, err := e.store.HMGet(cmd.Arguments[0], cmd.Arguments[1:])
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		items := make([][]byte, len(values))
+		for i, value := range values {
+			if value == nil {
+				items[i] = e.sr.GetNil()
+			} else {
+				items[i] = e.sr.GetBulkStringBytes(*value)
+			}
+		}
+
+		return e.sr.GetArrayBytes(items)
+
+	case actions.HIncrBy:
+		delta, err := strconv.ParseInt(cmd.Arguments[2], 10, 64)
+		if err != nil {
+			return e.sr.GetErrorBytes(errs.NotAnInteger.Error())
+		}
+
+		value, err := e.store.HIncrBy(cmd.Arguments[0], cmd.Arguments[1], delta)
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		return e.sr.GetIntegerBytes(int(value))
+
+	case actions.HIncrByFloat:
+		delta, err := strconv.ParseFloat(cmd.Arguments[2], 64)
+		if err != nil || math.IsNaN(delta) || math.IsInf(delta, 0) {
+			return e.sr.GetErrorBytes(errs.NotAFloat.Error())
+		}
+
+		value, err := e.store.HIncrByFloat(cmd.Arguments[0], cmd.Arguments[1], delta)
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		return e.sr.GetBulkStringBytes(store.FormatFloat(value))
+
+	case actions.HStrLen:
+		length, err := e.store.HStrLen(cmd.Arguments[0], cmd.Arguments[1])
+		if err == errs.ErrNotFound {
+			return e.sr.GetIntegerBytes(0)
+		}
+
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		return e.sr.GetIntegerBytes(length)
+
+	case actions.HRandField:
+		return e.hrandfield(cmd)
+
+	case actions.HExpire, actions.HPExpire, actions.HExpireAt, actions.HPExpireAt:
+		return e.hexpire(cmd)
+
+	case actions.HTTL, actions.HPTTL:
+		fields, err := parseHashFields(cmd.Arguments[1:])
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		ttls, err := e.store.HPTTL(cmd.Arguments[0], fields)
+		if err == errs.ErrNotFound {
+			return e.fieldCodesBytes(store.FieldNotFound, len(fields))
+		}
+
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		items := make([][]byte, len(ttls))
+		for i, ttl := range ttls {
+			// rounded to the nearest second, like TTL
+			if cmd.Action == actions.HTTL && ttl >= 0 {
+				ttl = (ttl + 500) / 1000
+			}
+			items[i] = e.sr.GetIntegerBytes(int(ttl))
+		}
+
+		return e.sr.GetArrayBytes(items)
+
+	case actions.HPersist:
+		fields, err := parseHashFields(cmd.Arguments[1:])
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		results, err := e.store.HPersist(cmd.Arguments[0], fields)
+		if err == errs.ErrNotFound {
+			return e.fieldCodesBytes(store.FieldNotFound, len(fields))
+		}
+
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		return e.integerArrayBytes(results)
+
+	default:
+		return e.sr.GetErrorBytes("ERR unknown command")
+	}
+}
@@ -0,0 +1,53 @@
+package executor
+
+import (
+	"math"
+	"server/clients"
+	"server/commands"
+	"server/commands/serializer"
+	"server/errs"
+	"server/store"
+	"server/store/actions"
+	"server/store/pubsub"
+	"strconv"
+	"testing"
+)
+
+func newTestExecutor() *Executor {
+	hub := pubsub.NewHub()
+	notifier := pubsub.NewNotifier(hub)
+	return NewExecutor(store.NewDatabases(store.DATABASES, notifier), hub, notifier)
+}
+
+func TestHRandFieldRejectsUnboundedNegativeCount(t *testing.T) {
+	e := newTestExecutor()
+	client := clients.NewClient()
+
+	e.ExecuteCommand(&commands.RedisCommand{Action: actions.HSet, Arguments: []string{"h", "f", "v"}}, client)
+
+	expected := string(serializer.NewSerializer().GetErrorBytes(errs.ValueOutOfRange.Error()))
+
+	for _, count := range []int{math.MinInt, -4000000000, -MAX_RANDOM_FIELD_REPEATS - 1} {
+		args := []string{"h", strconv.Itoa(count)}
+		reply := e.ExecuteCommand(&commands.RedisCommand{Action: actions.HRandField, Arguments: args}, client)
+
+		if string(reply) != expected {
+			t.Errorf("HRANDFIELD h %d replied %q, expected %q", count, reply, expected)
+		}
+	}
+}
+
+func TestHRandFieldNegativeCountRepeats(t *testing.T) {
+	e := newTestExecutor()
+	client := clients.NewClient()
+
+	e.ExecuteCommand(&commands.RedisCommand{Action: actions.HSet, Arguments: []string{"h", "f", "v"}}, client)
+
+	args := []string{"h", strconv.Itoa(-MAX_RANDOM_FIELD_REPEATS)}
+	reply := e.ExecuteCommand(&commands.RedisCommand{Action: actions.HRandField, Arguments: args}, client)
+
+	prefix := "*" + strconv.Itoa(MAX_RANDOM_FIELD_REPEATS) + "\r\n"
+	if len(reply) < len(prefix) || string(reply[:len(prefix)]) != prefix {
+		t.Errorf("HRANDFIELD h %d replied %.20q, expected an array of %d fields", -MAX_RANDOM_FIELD_REPEATS, reply, MAX_RANDOM_FIELD_REPEATS)
+	}
+}
@@ -0,0 +1,91 @@
+package executor
+
+import (
+	"server/commands"
+	"server/errs"
+	"server/store/actions"
+	"strings"
+)
+
+// ———————————————————————————————————————————————————————————————
+// Keyspace commands
+// ———————————————————————————————————————————————————————————————
+
+// COPY source destination [DB destination-db] [REPLACE]
+func (e *Executor) copy(cmd *commands.RedisCommand) []byte {
+	replace := false
+	target := e.store
+
+	for i := 2; i < len(cmd.Arguments); i++ {
+		switch option := strings.ToUpper(cmd.Arguments[i]); {
+		case option == "REPLACE":
+			replace = true
+
+		case option == "DB" && i+1 < len(cmd.Arguments):
+			db, err := e.parseDatabaseIndex(cmd.Arguments[i+1])
+			if err != nil {
+				return e.sr.GetErrorBytes(err.Error())
+			}
+			target = db
+			i++
+
+		default:
+			return e.sr.GetErrorBytes(errs.SyntaxError.Error())
+		}
+	}
+
+	copied, err := e.store.Copy(cmd.Arguments[0], cmd.Arguments[1], target, replace)
+	if err != nil {
+		return e.sr.GetErrorBytes(err.Error())
+	}
+
+	if copied {
+		return e.sr.GetIntegerBytes(1)
+	}
+	return e.sr.GetIntegerBytes(0)
+}
+
+func (e *Executor) executeKeyspaceCommand(cmd *commands.RedisCommand) []byte {
+	switch cmd.Action {
+	case actions.Keys:
+		return e.sr.GetArrayOfBulkStringBytes(e.store.Keys(cmd.Arguments[0]))
+
+	case actions.Type:
+		return e.sr.GetSimpleStringBytes(e.store.Type(cmd.Arguments[0]))
+
+	case actions.Rename, actions.RenameNX:
+		renamed, err := e.store.Rename(cmd.Arguments[0], cmd.Arguments[1], cmd.Action == actions.RenameNX)
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		if cmd.Action == actions.Rename {
+			return e.sr.GetSimpleStringBytes("OK")
+		}
+
+		if renamed {
+			return e.sr.GetIntegerBytes(1)
+		}
+		return e.sr.GetIntegerBytes(0)
+
+	case actions.RandomKey:
+		key, err := e.store.RandomKey()
+		if err == errs.ErrNotFound {
+			return e.sr.GetNil()
+		}
+
+		return e.sr.GetBulkStringBytes(key)
+
+	case actions.DBSize:
+		return e.sr.GetIntegerBytes(e.store.DBSize())
+
+	case actions.Touch:
+		return e.sr.GetIntegerBytes(e.store.Touch(cmd.Arguments))
+
+	case actions.Copy:
+		return e.copy(cmd)
+
+	default:
+		return e.sr.GetErrorBytes("ERR unknown command")
+	}
+}
@@ -0,0 +1,401 @@
+package executor
+
+import (
+	"server/clients"
+	"server/commands"
+	"server/errs"
+	"server/store/actions"
+	"server/store/objects"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ———————————————————————————————————————————————————————————————
+// List commands
+// ———————————————————————————————————————————————————————————————
+
+func (e *Executor) executeListCommand(cmd *commands.RedisCommand, client *clients.Client) []byte {
+	switch cmd.Action {
+	case actions.LLen:
+		length, err := e.store.LLen(cmd.Arguments[0])
+		if err == errs.ErrNotFound {
+			return e.sr.GetIntegerBytes(0)
+		}
+
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		return e.sr.GetIntegerBytes(length)
+
+	case actions.LRange:
+		start, startErr := strconv.Atoi(cmd.Arguments[1])
+		stop, stopErr := strconv.Atoi(cmd.Arguments[2])
+		if startErr != nil || stopErr != nil {
+			return e.sr.GetErrorBytes(errs.NotAnInteger.Error())
+		}
+
+		items, err := e.store.LRange(cmd.Arguments[0], start, stop)
+		if err == errs.ErrNotFound {
+			return e.sr.GetArrayOfBulkStringBytes([]string{})
+		}
+
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		return e.sr.GetArrayOfBulkStringBytes(items)
+
+	case actions.LIndex:
+		index, err := strconv.Atoi(cmd.Arguments[1])
+		if err != nil {
+			return e.sr.GetErrorBytes(errs.NotAnInteger.Error())
+		}
+
+		value, err := e.store.LIndex(cmd.Arguments[0], index)
+		if err == errs.ErrNotFound {
+			return e.sr.GetNil()
+		}
+
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		return e.sr.GetBulkStringBytes(value)
+
+	case actions.LSet:
+		index, err := strconv.Atoi(cmd.Arguments[1])
+		if err != nil {
+			return e.sr.GetErrorBytes(errs.NotAnInteger.Error())
+		}
+
+		err = e.store.LSet(cmd.Arguments[0], index, cmd.Arguments[2])
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		return e.sr.GetSimpleStringBytes("OK")
+
+	case actions.LInsert:
+		where := strings.ToUpper(cmd.Arguments[1])
+		if where != "BEFORE" && where != "AFTER" {
+			return e.sr.GetErrorBytes(errs.SyntaxError.Error())
+		}
+
+		length, err := e.store.LInsert(cmd.Arguments[0], where == "BEFORE", cmd.Arguments[2], cmd.Arguments[3])
+		if err == errs.ErrNotFound {
+			return e.sr.GetIntegerBytes(0)
+		}
+
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		return e.sr.GetIntegerBytes(length)
+
+	case actions.LRem:
+		count, err := strconv.Atoi(cmd.Arguments[1])
+		if err != nil {
+			return e.sr.GetErrorBytes(errs.NotAnInteger.Error())
+		}
+
+		removed, err := e.store.LRem(cmd.Arguments[0], count, cmd.Arguments[2])
+		if err == errs.ErrNotFound {
+			return e.sr.GetIntegerBytes(0)
+		}
+
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		return e.sr.GetIntegerBytes(removed)
+
+	case actions.LTrim:
+		start, startErr := strconv.Atoi(cmd.Arguments[1])
+		stop, stopErr := strconv.Atoi(cmd.Arguments[2])
+		if startErr != nil || stopErr != nil {
+			return e.sr.GetErrorBytes(errs.NotAnInteger.Error())
+		}
+
+		err := e.store.LTrim(cmd.Arguments[0], start, stop)
+		if err != nil && err != errs.ErrNotFound {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		return e.sr.GetSimpleStringBytes("OK")
+
+	case actions.LPos:
+		return e.lpos(cmd)
+
+	case actions.LMove, actions.BLMove, actions.RPopLPush, actions.BRPopLPush:
+		return e.lmove(cmd, client)
+
+	case actions.BLPop, actions.BRPop:
+		return e.blpop(cmd, client)
+
+	case actions.LMPop, actions.BLMPop:
+		return e.lmpop(cmd, client)
+
+	default:
+		return e.sr.GetErrorBytes("ERR unknown command")
+	}
+}
+
+// LPOS key element [RANK rank] [COUNT num-matches] [MAXLEN len]
+func (e *Executor) lpos(cmd *commands.RedisCommand) []byte {
+	rank, count, maxLen := 1, -1, 0
+
+	for i := 2; i < len(cmd.Arguments); i += 2 {
+		if i+1 >= len(cmd.Arguments) {
+			return e.sr.GetErrorBytes(errs.SyntaxError.Error())
+		}
+
+		n, err := strconv.Atoi(cmd.Arguments[i+1])
+		if err != nil {
+			return e.sr.GetErrorBytes(errs.NotAnInteger.Error())
+		}
+
+		switch strings.ToUpper(cmd.Arguments[i]) {
+		case "RANK":
+			if n == 0 {
+				return e.sr.GetErrorBytes("RANK CAN'T BE ZERO: USE 1 TO START FROM THE FIRST MATCH, 2 FROM THE SECOND ONE OR USE NEGATIVE TO START FROM THE END OF THE LIST")
+			}
+			rank = n
+		case "COUNT":
+			if n < 0 {
+				return e.sr.GetErrorBytes("COUNT CAN'T BE NEGATIVE")
+			}
+			count = n
+		case "MAXLEN":
+			if n < 0 {
+				return e.sr.GetErrorBytes("MAXLEN CAN'T BE NEGATIVE")
+			}
+			maxLen = n
+		default:
+			return e.sr.GetErrorBytes(errs.SyntaxError.Error())
+		}
+	}
+
+	// without COUNT only the first match is returned, as an integer
+	limit := count
+	if count == -1 {
+		limit = 1
+	}
+
+	positions, err := e.store.LPos(cmd.Arguments[0], cmd.Arguments[1], rank, limit, maxLen)
+	if err != nil && err != errs.ErrNotFound {
+		return e.sr.GetErrorBytes(err.Error())
+	}
+
+	if count == -1 {
+		if len(positions) == 0 {
+			return e.sr.GetNil()
+		}
+		return e.sr.GetIntegerBytes(positions[0])
+	}
+
+	items := make([][]byte, len(positions))
+	for i, position := range positions {
+		items[i] = e.sr.GetIntegerBytes(position)
+	}
+
+	return e.sr.GetArrayBytes(items)
+}
+
+// LMOVE source destination LEFT|RIGHT LEFT|RIGHT, BLMOVE adds a timeout,
+// RPOPLPUSH source destination and BRPOPLPUSH source destination timeout
+func (e *Executor) lmove(cmd *commands.RedisCommand, client *clients.Client) []byte {
+	source, destination := cmd.Arguments[0], cmd.Arguments[1]
+	from, to := actions.BRIGHT, actions.BLEFT
+
+	if cmd.Action == actions.LMove || cmd.Action == actions.BLMove {
+		var ok bool
+		from, ok = parseListDirection(cmd.Arguments[2])
+		if !ok {
+			return e.sr.GetErrorBytes(errs.SyntaxError.Error())
+		}
+
+		to, ok = parseListDirection(cmd.Arguments[3])
+		if !ok {
+			return e.sr.GetErrorBytes(errs.SyntaxError.Error())
+		}
+	}
+
+	var value string
+	var err error
+
+	switch cmd.Action {
+	case actions.BLMove, actions.BRPopLPush:
+		timeout, timeoutErr := parseTimeout(cmd.Arguments[len(cmd.Arguments)-1])
+		if timeoutErr != nil {
+			return e.sr.GetErrorBytes(timeoutErr.Error())
+		}
+
+		ctx, release := client.Block()
+		defer release()
+
+		var result objects.ListPopResult
+		result, err = e.store.BLMove(ctx, source, destination, from, to, timeout)
+		if err == nil {
+			value = result.Values[0]
+			cmd.Logged = result.Logged
+		}
+	default:
+		value, err = e.store.LMove(source, destination, from, to)
+	}
+
+	if err == errs.ErrNotFound || err == errs.Timeout {
+		return e.sr.GetNil()
+	}
+
+	if err != nil {
+		return e.sr.GetErrorBytes(err.Error())
+	}
+
+	// the blocking forms are logged as the move they made
+	if cmd.Action == actions.BLMove || cmd.Action == actions.BRPopLPush {
+		cmd.Action = actions.LMove
+		cmd.Arguments = []string{source, destination, listDirectionName(from), listDirectionName(to)}
+	}
+
+	return e.sr.GetBulkStringBytes(value)
+}
+
+func parseListDirection(s string) (actions.BlockingPopDirection, bool) {
+	switch strings.ToUpper(s) {
+	case "LEFT":
+		return actions.BLEFT, true
+	case "RIGHT":
+		return actions.BRIGHT, true
+	default:
+		return "", false
+	}
+}
+
+func listDirectionName(direction actions.BlockingPopDirection) string {
+	if direction == actions.BLEFT {
+		return "LEFT"
+	}
+	return "RIGHT"
+}
+
+// BLPOP key [key ...] timeout, replies with the key and the element
+func (e *Executor) blpop(cmd *commands.RedisCommand, client *clients.Client) []byte {
+	last := len(cmd.Arguments) - 1
+	keys := cmd.Arguments[:last]
+
+	timeout, err := parseTimeout(cmd.Arguments[last])
+	if err != nil {
+		return e.sr.GetErrorBytes(err.Error())
+	}
+
+	ctx, release := client.Block()
+	defer release()
+
+	var result objects.ListPopResult
+	if cmd.Action == actions.BLPop {
+		result, err = e.store.BLPop(ctx, keys, timeout)
+	} else {
+		result, err = e.store.BRPop(ctx, keys, timeout)
+	}
+
+	if err == errs.Timeout {
+		return e.sr.GetNilArray()
+	}
+
+	if err != nil {
+		return e.sr.GetErrorBytes(err.Error())
+	}
+
+	// logged as the pop it resolved to, replaying a blocking pop could block forever
+	if cmd.Action == actions.BLPop {
+		cmd.Action = actions.LPop
+	} else {
+		cmd.Action = actions.RPop
+	}
+	cmd.Arguments = []string{result.Key}
+	cmd.Logged = result.Logged
+
+	return e.sr.GetArrayOfBulkStringBytes([]string{result.Key, result.Values[0]})
+}
+
+// LMPOP numkeys key [key ...] LEFT|RIGHT [COUNT count]
+// BLMPOP timeout numkeys key [key ...] LEFT|RIGHT [COUNT count]
+func (e *Executor) lmpop(cmd *commands.RedisCommand, client *clients.Client) []byte {
+	args := cmd.Arguments
+
+	var timeout time.Duration
+	if cmd.Action == actions.BLMPop {
+		var err error
+		timeout, err = parseTimeout(args[0])
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+		args = args[1:]
+	}
+
+	numKeys, err := strconv.Atoi(args[0])
+	if err != nil || numKeys <= 0 {
+		return e.sr.GetErrorBytes("NUMKEYS SHOULD BE GREATER THAN 0")
+	}
+
+	if len(args) < numKeys+2 {
+		return e.sr.GetErrorBytes(errs.SyntaxError.Error())
+	}
+
+	keys := args[1 : numKeys+1]
+	direction, ok := parseListDirection(args[numKeys+1])
+	if !ok {
+		return e.sr.GetErrorBytes(errs.SyntaxError.Error())
+	}
+
+	count := 1
+	options := args[numKeys+2:]
+
+	switch {
+	case len(options) == 0:
+		;
+	case len(options) == 2 && strings.ToUpper(options[0]) == "COUNT":
+		count, err = strconv.Atoi(options[1])
+		if err != nil || count <= 0 {
+			return e.sr.GetErrorBytes("COUNT SHOULD BE GREATER THAN 0")
+		}
+	default:
+		return e.sr.GetErrorBytes(errs.SyntaxError.Error())
+	}
+
+	var result objects.ListPopResult
+	if cmd.Action == actions.BLMPop {
+		ctx, release := client.Block()
+		defer release()
+
+		result, err = e.store.BLMPop(ctx, keys, direction, count, timeout)
+	} else {
+		result, err = e.store.LMPop(keys, direction, count)
+	}
+
+	if err == errs.ErrNotFound || err == errs.Timeout {
+		return e.sr.GetNilArray()
+	}
+
+	if err != nil {
+		return e.sr.GetErrorBytes(err.Error())
+	}
+
+	// BLMPOP is logged as the pop it resolved to
+	if cmd.Action == actions.BLMPop {
+		if direction == actions.BLEFT {
+			cmd.Action = actions.LPop
+		} else {
+			cmd.Action = actions.RPop
+		}
+		cmd.Arguments = []string{result.Key, strconv.Itoa(len(result.Values))}
+		cmd.Logged = result.Logged
+	}
+
+	return e.sr.GetArrayBytes([][]byte{
+		e.sr.GetBulkStringBytes(result.Key),
+		e.sr.GetArrayOfBulkStringBytes(result.Values),
+	})
+}
@@ -0,0 +1,150 @@
+package executor
+
+import (
+	"server/clients"
+	"server/cluster"
+	"server/commands"
+	"server/errs"
+	"server/store/actions"
+	"strings"
+)
+
+// ———————————————————————————————————————————————————————————————
+// Pub/Sub commands
+// ———————————————————————————————————————————————————————————————
+
+// subscriptionBytes is the frame confirming a subscription change, channel is nil when
+// an unsubscribe had nothing to remove
+func (e *Executor) subscriptionBytes(kind string, channel *string, count int) []byte {
+	channelBytes := e.sr.GetNil()
+	if channel != nil {
+		channelBytes = e.sr.GetBulkStringBytes(*channel)
+	}
+
+	return e.sr.GetArrayBytes([][]byte{
+		e.sr.GetBulkStringBytes(kind),
+		channelBytes,
+		e.sr.GetIntegerBytes(count),
+	})
+}
+
+// subscriptionsBytes confirms each channel in its own frame, the frames are written back to back
+func (e *Executor) subscriptionsBytes(kind string, channels []string, counts []int) []byte {
+	if len(channels) == 0 {
+		return e.subscriptionBytes(kind, nil, 0)
+	}
+
+	response := []byte{}
+	for i := range channels {
+		response = append(response, e.subscriptionBytes(kind, &channels[i], counts[i])...)
+	}
+
+	return response
+}
+
+func (e *Executor) executePubSubCommand(cmd *commands.RedisCommand, client *clients.Client) []byte {
+	switch cmd.Action {
+	case actions.Subscribe:
+		counts := e.hub.Subscribe(client.Subscriber(), cmd.Arguments)
+		return e.subscriptionsBytes("subscribe", cmd.Arguments, counts)
+
+	case actions.Unsubscribe:
+		channels, counts := e.hub.Unsubscribe(client.Subscriber(), cmd.Arguments)
+		return e.subscriptionsBytes("unsubscribe", channels, counts)
+
+	case actions.PSubscribe:
+		counts := e.hub.PSubscribe(client.Subscriber(), cmd.Arguments)
+		return e.subscriptionsBytes("psubscribe", cmd.Arguments, counts)
+
+	case actions.PUnsubscribe:
+		patterns, counts := e.hub.PUnsubscribe(client.Subscriber(), cmd.Arguments)
+		return e.subscriptionsBytes("punsubscribe", patterns, counts)
+
+	case actions.SSubscribe:
+		if err := sameSlot(cmd.Arguments); err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		counts := e.hub.SSubscribe(client.Subscriber(), cmd.Arguments)
+		return e.subscriptionsBytes("ssubscribe", cmd.Arguments, counts)
+
+	case actions.SUnsubscribe:
+		if err := sameSlot(cmd.Arguments); err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		channels, counts := e.hub.SUnsubscribe(client.Subscriber(), cmd.Arguments)
+		return e.subscriptionsBytes("sunsubscribe", channels, counts)
+
+	case actions.Publish:
+		return e.sr.GetIntegerBytes(e.hub.Publish(cmd.Arguments[0], cmd.Arguments[1]))
+
+	case actions.SPublish:
+		return e.sr.GetIntegerBytes(e.hub.SPublish(cmd.Arguments[0], cmd.Arguments[1]))
+
+	case actions.PubSub:
+		return e.pubsub(cmd)
+	}
+
+	return nil
+}
+
+// sameSlot rejects shard channels spread over several hash slots, they could not be
+// served by a single node of a cluster
+func sameSlot(channels []string) error {
+	for _, channel := range channels {
+		if cluster.KeySlot(channel) != cluster.KeySlot(channels[0]) {
+			return errs.CrossSlot
+		}
+	}
+
+	return nil
+}
+
+// PUBSUB CHANNELS [pattern], PUBSUB NUMSUB [channel ...], PUBSUB NUMPAT
+// PUBSUB SHARDCHANNELS [pattern], PUBSUB SHARDNUMSUB [channel ...]
+func (e *Executor) pubsub(cmd *commands.RedisCommand) []byte {
+	switch subcommand := strings.ToUpper(cmd.Arguments[0]); subcommand {
+	case "CHANNELS", "SHARDCHANNELS":
+		if len(cmd.Arguments) > 2 {
+			return e.sr.GetErrorBytes(errs.IncorrectNumberOfArguments.Error())
+		}
+
+		pattern := ""
+		if len(cmd.Arguments) == 2 {
+			pattern = cmd.Arguments[1]
+		}
+
+		if subcommand == "SHARDCHANNELS" {
+			return e.sr.GetArrayOfBulkStringBytes(e.hub.ShardChannels(pattern))
+		}
+		return e.sr.GetArrayOfBulkStringBytes(e.hub.Channels(pattern))
+
+	case "NUMSUB", "SHARDNUMSUB":
+		channels := cmd.Arguments[1:]
+
+		var counts []int
+		if subcommand == "SHARDNUMSUB" {
+			counts = e.hub.ShardNumSub(channels)
+		} else {
+			counts = e.hub.NumSub(channels)
+		}
+
+		items := make([][]byte, 0, 2*len(channels))
+		for i, channel := range channels {
+			items = append(items, e.sr.GetBulkStringBytes(channel), e.sr.GetIntegerBytes(counts[i]))
+		}
+
+		return e.sr.GetArrayBytes(items)
+
+	case "NUMPAT":
+		if len(cmd.Arguments) != 1 {
+			return e.sr.GetErrorBytes(errs.IncorrectNumberOfArguments.Error())
+		}
+
+		return e.sr.GetIntegerBytes(e.hub.NumPat())
+
+	default:
+		return e.sr.GetErrorBytes(errs.SyntaxError.Error())
+	}
+}
@@ -0,0 +1,106 @@
+package executor
+
+import (
+	"errors"
+	"server/commands"
+	"server/errs"
+	"server/store"
+	"server/store/actions"
+	"server/store/objects"
+	"strconv"
+	"strings"
+)
+
+// ———————————————————————————————————————————————————————————————
+// Scan commands
+// ———————————————————————————————————————————————————————————————
+
+const DEFAULT_SCAN_COUNT = 10
+
+var invalidCursor = errors.New("INVALID CURSOR")
+
+// SCAN cursor [MATCH pattern] [COUNT count] [TYPE type]
+// HSCAN key cursor [MATCH pattern] [COUNT count] [NOVALUES]
+// SSCAN key cursor [MATCH pattern] [COUNT count], ZSCAN likewise
+func (e *Executor) executeScanCommand(cmd *commands.RedisCommand) []byte {
+	cursorAt := 1
+	if cmd.Action == actions.Scan {
+		cursorAt = 0
+	}
+
+	cursor, err := strconv.ParseUint(cmd.Arguments[cursorAt], 10, 64)
+	if err != nil {
+		return e.sr.GetErrorBytes(invalidCursor.Error())
+	}
+
+	options := store.ScanOptions{Count: DEFAULT_SCAN_COUNT}
+	noValues := false
+
+	for i := cursorAt + 1; i < len(cmd.Arguments); i++ {
+		option := strings.ToUpper(cmd.Arguments[i])
+
+		switch {
+		case option == "NOVALUES" && cmd.Action == actions.HScan:
+			noValues = true
+
+		case option == "MATCH" && i+1 < len(cmd.Arguments):
+			options.Match = cmd.Arguments[i+1]
+			i++
+
+		case option == "COUNT" && i+1 < len(cmd.Arguments):
+			count, err := strconv.Atoi(cmd.Arguments[i+1])
+			if err != nil {
+				return e.sr.GetErrorBytes(errs.NotAnInteger.Error())
+			}
+			if count < 1 {
+				return e.sr.GetErrorBytes(errs.SyntaxError.Error())
+			}
+			options.Count = count
+			i++
+
+		case option == "TYPE" && cmd.Action == actions.Scan && i+1 < len(cmd.Arguments):
+			options.DataType = objects.DataType(strings.ToLower(cmd.Arguments[i+1]))
+			i++
+
+		default:
+			return e.sr.GetErrorBytes(errs.SyntaxError.Error())
+		}
+	}
+
+	var items []string
+	var next uint64
+
+	switch cmd.Action {
+	case actions.Scan:
+		items, next = e.store.Scan(cursor, options)
+
+	case actions.HScan:
+		items, next, err = e.store.HScan(cmd.Arguments[0], cursor, options, noValues)
+
+	case actions.SScan:
+		items, next, err = e.store.SScan(cmd.Arguments[0], cursor, options)
+
+	case actions.ZScan:
+		var members []objects.ScoredMember
+		members, next, err = e.store.ZScan(cmd.Arguments[0], cursor, options)
+
+		for _, m := range members {
+			items = append(items, m.Member, objects.FormatScore(m.Score))
+		}
+	}
+
+	if err == errs.ErrNotFound {
+		items, next = []string{}, 0
+	} else if err != nil {
+		return e.sr.GetErrorBytes(err.Error())
+	}
+
+	if items == nil {
+		items = []string{}
+	}
+
+	return e.sr.GetArrayBytes([][]byte{
+		e.sr.GetBulkStringBytes(strconv.FormatUint(next, 10)),
+		e.sr.GetArrayOfBulkStringBytes(items),
+	})
+}
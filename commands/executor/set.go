@@ -0,0 +1,115 @@
+package executor
+
+import (
+	"server/commands"
+	"server/errs"
+	"server/store/actions"
+)
+
+// ———————————————————————————————————————————————————————————————
+// Set commands
+// ———————————————————————————————————————————————————————————————
+
+func (e *Executor) executeSetCommand(cmd *commands.RedisCommand) []byte {
+	switch cmd.Action {
+	case actions.SAdd:
+		cnt, err := e.store.SAdd(cmd.Arguments[0], cmd.Arguments[1:])
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		return e.sr.GetIntegerBytes(cnt)
+
+	case actions.SRem:
+		cnt, err := e.store.SRem(cmd.Arguments[0], cmd.Arguments[1:])
+		if err == errs.ErrNotFound {
+			return e.sr.GetIntegerBytes(0)
+		}
+
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		return e.sr.GetIntegerBytes(cnt)
+
+	case actions.SMembers:
+		members, err := e.store.SMembers(cmd.Arguments[0])
+		if err == errs.ErrNotFound {
+			return e.sr.GetArrayOfBulkStringBytes([]string{})
+		}
+
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		return e.sr.GetArrayOfBulkStringBytes(members)
+
+	case actions.SIsMember:
+		isMember, err := e.store.SIsMember(cmd.Arguments[0], cmd.Arguments[1])
+		if err == errs.ErrNotFound {
+			return e.sr.GetIntegerBytes(0)
+		}
+
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		if isMember {
+			return e.sr.GetIntegerBytes(1)
+		}
+		return e.sr.GetIntegerBytes(0)
+
+	case actions.SCard:
+		cnt, err := e.store.SCard(cmd.Arguments[0])
+		if err == errs.ErrNotFound {
+			return e.sr.GetIntegerBytes(0)
+		}
+
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		return e.sr.GetIntegerBytes(cnt)
+
+	case actions.SInter, actions.SUnion, actions.SDiff:
+		var members []string
+		var err error
+
+		switch cmd.Action {
+		case actions.SInter:
+			members, err = e.store.SInter(cmd.Arguments)
+		case actions.SUnion:
+			members, err = e.store.SUnion(cmd.Arguments)
+		default:
+			members, err = e.store.SDiff(cmd.Arguments)
+		}
+
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		return e.sr.GetArrayOfBulkStringBytes(members)
+
+	case actions.SInterStore, actions.SUnionStore, actions.SDiffStore:
+		var cnt int
+		var err error
+
+		switch cmd.Action {
+		case actions.SInterStore:
+			cnt, err = e.store.SInterStore(cmd.Arguments[0], cmd.Arguments[1:])
+		case actions.SUnionStore:
+			cnt, err = e.store.SUnionStore(cmd.Arguments[0], cmd.Arguments[1:])
+		default:
+			cnt, err = e.store.SDiffStore(cmd.Arguments[0], cmd.Arguments[1:])
+		}
+
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		return e.sr.GetIntegerBytes(cnt)
+
+	default:
+		return e.sr.GetErrorBytes("ERR unknown command")
+	}
+}
@@ -0,0 +1,384 @@
+package executor
+
+import (
+	"server/clients"
+	"server/commands"
+	"server/errs"
+	"server/store"
+	"server/store/actions"
+	"server/store/objects"
+	"strconv"
+	"strings"
+)
+
+// ———————————————————————————————————————————————————————————————
+// Sorted set commands
+// ———————————————————————————————————————————————————————————————
+
+func (e *Executor) executeSortedSetCommand(cmd *commands.RedisCommand, client *clients.Client) []byte {
+	switch cmd.Action {
+	case actions.ZAdd:
+		return e.zadd(cmd)
+
+	case actions.ZIncrBy:
+		increment, err := objects.ParseScore(cmd.Arguments[1])
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		score, err := e.store.ZIncrBy(cmd.Arguments[0], increment, cmd.Arguments[2])
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		return e.sr.GetBulkStringBytes(objects.FormatScore(score))
+
+	case actions.ZRange:
+		return e.zrange(cmd)
+
+	case actions.ZRank, actions.ZRevRank:
+		rank, err := e.store.ZRank(cmd.Arguments[0], cmd.Arguments[1], cmd.Action == actions.ZRevRank)
+		if err == errs.ErrNotFound {
+			return e.sr.GetNil()
+		}
+
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		return e.sr.GetIntegerBytes(rank)
+
+	case actions.ZScore:
+		score, err := e.store.ZScore(cmd.Arguments[0], cmd.Arguments[1])
+		if err == errs.ErrNotFound {
+			return e.sr.GetNil()
+		}
+
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		return e.sr.GetBulkStringBytes(objects.FormatScore(score))
+
+	case actions.ZRem:
+		cnt, err := e.store.ZRem(cmd.Arguments[0], cmd.Arguments[1:])
+		if err == errs.ErrNotFound {
+			return e.sr.GetIntegerBytes(0)
+		}
+
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		return e.sr.GetIntegerBytes(cnt)
+
+	case actions.ZCard:
+		cnt, err := e.store.ZCard(cmd.Arguments[0])
+		if err == errs.ErrNotFound {
+			return e.sr.GetIntegerBytes(0)
+		}
+
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		return e.sr.GetIntegerBytes(cnt)
+
+	case actions.ZPopMin, actions.ZPopMax:
+		count := 1
+		if len(cmd.Arguments) == 2 {
+			var err error
+			count, err = strconv.Atoi(cmd.Arguments[1])
+			if err != nil || count < 0 {
+				return e.sr.GetErrorBytes("COUNT MUST BE A POSITIVE INTEGER")
+			}
+		}
+
+		popped, err := e.store.ZPop(cmd.Arguments[0], count, cmd.Action == actions.ZPopMax)
+		if err == errs.ErrNotFound {
+			return e.sr.GetArrayOfBulkStringBytes([]string{})
+		}
+
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		return e.sr.GetArrayOfBulkStringBytes(flattenScoredMembers(popped, true))
+
+	case actions.BZPopMin, actions.BZPopMax:
+		return e.bzpop(cmd, client)
+
+	case actions.ZRemRangeByRank:
+		start, startErr := strconv.Atoi(cmd.Arguments[1])
+		stop, stopErr := strconv.Atoi(cmd.Arguments[2])
+		if startErr != nil || stopErr != nil {
+			return e.sr.GetErrorBytes(errs.NotAnInteger.Error())
+		}
+
+		return e.zremRangeReply(e.store.ZRemRangeByRank(cmd.Arguments[0], start, stop))
+
+	case actions.ZRemRangeByScore:
+		r, err := objects.ParseScoreRange(cmd.Arguments[1], cmd.Arguments[2])
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		return e.zremRangeReply(e.store.ZRemRangeByScore(cmd.Arguments[0], r))
+
+	case actions.ZRemRangeByLex:
+		r, err := objects.ParseLexRange(cmd.Arguments[1], cmd.Arguments[2])
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		return e.zremRangeReply(e.store.ZRemRangeByLex(cmd.Arguments[0], r))
+
+	default:
+		return e.sr.GetErrorBytes("ERR unknown command")
+	}
+}
+
+func (e *Executor) zremRangeReply(cnt int, err error) []byte {
+	if err == errs.ErrNotFound {
+		return e.sr.GetIntegerBytes(0)
+	}
+
+	if err != nil {
+		return e.sr.GetErrorBytes(err.Error())
+	}
+
+	return e.sr.GetIntegerBytes(cnt)
+}
+
+// BZPOPMIN key [key ...] timeout
+func (e *Executor) bzpop(cmd *commands.RedisCommand, client *clients.Client) []byte {
+	last := len(cmd.Arguments) - 1
+	keys := cmd.Arguments[:last]
+
+	timeout, err := parseTimeout(cmd.Arguments[last])
+	if err != nil {
+		return e.sr.GetErrorBytes(err.Error())
+	}
+
+	ctx, release := client.Block()
+	defer release()
+
+	var result objects.ZSetPopResult
+	if cmd.Action == actions.BZPopMax {
+		result, err = e.store.BZPopMax(ctx, keys, timeout)
+	} else {
+		result, err = e.store.BZPopMin(ctx, keys, timeout)
+	}
+
+	if err == errs.Timeout {
+		return e.sr.GetNilArray()
+	}
+
+	if err != nil {
+		return e.sr.GetErrorBytes(err.Error())
+	}
+
+	// propagate the pop that actually happened, so that an AOF replay never blocks
+	if cmd.Action == actions.BZPopMax {
+		cmd.Action = actions.ZPopMax
+	} else {
+		cmd.Action = actions.ZPopMin
+	}
+	cmd.Arguments = []string{result.Key, "1"}
+	cmd.Logged = result.Logged
+
+	return e.sr.GetArrayOfBulkStringBytes([]string{
+		result.Key,
+		result.Member.Member,
+		objects.FormatScore(result.Member.Score),
+	})
+}
+
+// ZADD key [NX|XX] [GT|LT] [CH] [INCR] score member [score member ...]
+func (e *Executor) zadd(cmd *commands.RedisCommand) []byte {
+	options := store.ZAddOptions{}
+
+	// options come first, the score/member pairs start at the first non-option
+	i := 1
+	for isOption := true; isOption && i < len(cmd.Arguments); i++ {
+		switch strings.ToUpper(cmd.Arguments[i]) {
+		case "NX":
+			options.NX = true
+		case "XX":
+			options.XX = true
+		case "GT":
+			options.GT = true
+		case "LT":
+			options.LT = true
+		case "CH":
+			options.CH = true
+		case "INCR":
+			options.Incr = true
+		default:
+			isOption = false
+			i--
+		}
+	}
+
+	pairs := cmd.Arguments[i:]
+	if len(pairs) == 0 || len(pairs)%2 != 0 {
+		return e.sr.GetErrorBytes(errs.SyntaxError.Error())
+	}
+
+	if options.NX && options.XX {
+		return e.sr.GetErrorBytes("XX AND NX OPTIONS AT THE SAME TIME ARE NOT COMPATIBLE")
+	}
+
+	if (options.GT && options.LT) || (options.NX && (options.GT || options.LT)) {
+		return e.sr.GetErrorBytes("GT, LT, AND/OR NX OPTIONS AT THE SAME TIME ARE NOT COMPATIBLE")
+	}
+
+	if options.Incr && len(pairs) != 2 {
+		return e.sr.GetErrorBytes("INCR OPTION SUPPORTS A SINGLE INCREMENT-ELEMENT PAIR")
+	}
+
+	members := make([]objects.ScoredMember, 0, len(pairs)/2)
+	for j := 0; j < len(pairs); j += 2 {
+		score, err := objects.ParseScore(pairs[j])
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		members = append(members, objects.ScoredMember{Member: pairs[j+1], Score: score})
+	}
+
+	if options.Incr {
+		score, applied, err := e.store.ZAddIncr(cmd.Arguments[0], options, members[0].Member, members[0].Score)
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		if !applied {
+			return e.sr.GetNil()
+		}
+
+		return e.sr.GetBulkStringBytes(objects.FormatScore(score))
+	}
+
+	cnt, err := e.store.ZAdd(cmd.Arguments[0], options, members)
+	if err != nil {
+		return e.sr.GetErrorBytes(err.Error())
+	}
+
+	return e.sr.GetIntegerBytes(cnt)
+}
+
+// ZRANGE key start stop [BYSCORE|BYLEX] [REV] [LIMIT offset count] [WITHSCORES]
+func (e *Executor) zrange(cmd *commands.RedisCommand) []byte {
+	key, start, stop := cmd.Arguments[0], cmd.Arguments[1], cmd.Arguments[2]
+
+	byScore, byLex, reverse, withScores, limit := false, false, false, false, false
+	offset, count := 0, -1
+
+	for i := 3; i < len(cmd.Arguments); i++ {
+		switch strings.ToUpper(cmd.Arguments[i]) {
+		case "BYSCORE":
+			byScore = true
+		case "BYLEX":
+			byLex = true
+		case "REV":
+			reverse = true
+		case "WITHSCORES":
+			withScores = true
+		case "LIMIT":
+			if i+2 >= len(cmd.Arguments) {
+				return e.sr.GetErrorBytes(errs.SyntaxError.Error())
+			}
+
+			var err error
+			offset, err = strconv.Atoi(cmd.Arguments[i+1])
+			if err != nil {
+				return e.sr.GetErrorBytes(errs.NotAnInteger.Error())
+			}
+
+			count, err = strconv.Atoi(cmd.Arguments[i+2])
+			if err != nil {
+				return e.sr.GetErrorBytes(errs.NotAnInteger.Error())
+			}
+
+			limit = true
+			i += 2
+		default:
+			return e.sr.GetErrorBytes(errs.SyntaxError.Error())
+		}
+	}
+
+	if byScore && byLex {
+		return e.sr.GetErrorBytes(errs.SyntaxError.Error())
+	}
+
+	if limit && !byScore && !byLex {
+		return e.sr.GetErrorBytes("SYNTAX ERROR, LIMIT IS ONLY SUPPORTED IN COMBINATION WITH EITHER BYSCORE OR BYLEX")
+	}
+
+	if withScores && byLex {
+		return e.sr.GetErrorBytes("SYNTAX ERROR, WITHSCORES NOT SUPPORTED IN COMBINATION WITH BYLEX")
+	}
+
+	// reversed score and lex ranges are given as max min
+	if reverse && (byScore || byLex) {
+		start, stop = stop, start
+	}
+
+	var members []objects.ScoredMember
+	var err error
+
+	switch {
+	case byScore:
+		r, parseErr := objects.ParseScoreRange(start, stop)
+		if parseErr != nil {
+			return e.sr.GetErrorBytes(parseErr.Error())
+		}
+
+		members, err = e.store.ZRangeByScore(key, r, reverse, offset, count)
+
+	case byLex:
+		r, parseErr := objects.ParseLexRange(start, stop)
+		if parseErr != nil {
+			return e.sr.GetErrorBytes(parseErr.Error())
+		}
+
+		members, err = e.store.ZRangeByLex(key, r, reverse, offset, count)
+
+	default:
+		startIndex, startErr := strconv.Atoi(start)
+		stopIndex, stopErr := strconv.Atoi(stop)
+		if startErr != nil || stopErr != nil {
+			return e.sr.GetErrorBytes(errs.NotAnInteger.Error())
+		}
+
+		members, err = e.store.ZRangeByRank(key, startIndex, stopIndex, reverse)
+	}
+
+	if err == errs.ErrNotFound {
+		return e.sr.GetArrayOfBulkStringBytes([]string{})
+	}
+
+	if err != nil {
+		return e.sr.GetErrorBytes(err.Error())
+	}
+
+	return e.sr.GetArrayOfBulkStringBytes(flattenScoredMembers(members, withScores))
+}
+
+func flattenScoredMembers(members []objects.ScoredMember, withScores bool) []string {
+	if !withScores {
+		result := make([]string, len(members))
+		for i, m := range members {
+			result[i] = m.Member
+		}
+		return result
+	}
+
+	result := make([]string, 0, 2*len(members))
+	for _, m := range members {
+		result = append(result, m.Member, objects.FormatScore(m.Score))
+	}
+
+	return result
+}
@@ -0,0 +1,343 @@
+package executor
+
+import (
+	"server/clients"
+	"server/commands"
+	"server/errs"
+	"server/store"
+	"server/store/actions"
+	"server/store/objects"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ———————————————————————————————————————————————————————————————
+// Stream commands
+// ———————————————————————————————————————————————————————————————
+
+func (e *Executor) executeStreamCommand(cmd *commands.RedisCommand, client *clients.Client) []byte {
+	switch cmd.Action {
+	case actions.XAdd:
+		return e.xadd(cmd)
+
+	case actions.XRange, actions.XRevRange:
+		return e.xrange(cmd)
+
+	case actions.XLen:
+		length, err := e.store.XLen(cmd.Arguments[0])
+		if err == errs.ErrNotFound {
+			return e.sr.GetIntegerBytes(0)
+		}
+
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		return e.sr.GetIntegerBytes(length)
+
+	case actions.XDel:
+		ids := make([]objects.StreamID, len(cmd.Arguments)-1)
+		for i, arg := range cmd.Arguments[1:] {
+			id, err := objects.ParseStreamID(arg, 0)
+			if err != nil {
+				return e.sr.GetErrorBytes(err.Error())
+			}
+			ids[i] = id
+		}
+
+		cnt, err := e.store.XDel(cmd.Arguments[0], ids)
+		if err == errs.ErrNotFound {
+			return e.sr.GetIntegerBytes(0)
+		}
+
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		return e.sr.GetIntegerBytes(cnt)
+
+	case actions.XTrim:
+		trim, consumed, err := parseStreamTrim(cmd.Arguments[1:])
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		if consumed != len(cmd.Arguments)-1 {
+			return e.sr.GetErrorBytes(errs.SyntaxError.Error())
+		}
+
+		cnt, err := e.store.XTrim(cmd.Arguments[0], trim)
+		if err == errs.ErrNotFound {
+			return e.sr.GetIntegerBytes(0)
+		}
+
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		return e.sr.GetIntegerBytes(cnt)
+
+	case actions.XRead:
+		return e.xread(cmd, client)
+
+	default:
+		return e.sr.GetErrorBytes("ERR unknown command")
+	}
+}
+
+// XADD key [NOMKSTREAM] [MAXLEN|MINID [=|~] threshold [LIMIT count]] *|id field value [field value ...]
+func (e *Executor) xadd(cmd *commands.RedisCommand) []byte {
+	noMkStream := false
+	var trim *objects.StreamTrim
+
+	i := 1
+	for ; i < len(cmd.Arguments); i++ {
+		option := strings.ToUpper(cmd.Arguments[i])
+
+		if option == "NOMKSTREAM" {
+			noMkStream = true
+			continue
+		}
+
+		if option != string(objects.TrimMaxLen) && option != string(objects.TrimMinID) {
+			break
+		}
+
+		parsed, consumed, err := parseStreamTrim(cmd.Arguments[i:])
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		trim = &parsed
+		i += consumed - 1
+	}
+
+	if i >= len(cmd.Arguments) {
+		return e.sr.GetErrorBytes(errs.IncorrectNumberOfArguments.Error())
+	}
+
+	fields := cmd.Arguments[i+1:]
+	if len(fields) == 0 || len(fields)%2 != 0 {
+		return e.sr.GetErrorBytes(errs.IncorrectNumberOfArguments.Error())
+	}
+
+	id, err := e.store.XAdd(cmd.Arguments[0], cmd.Arguments[i], fields, noMkStream, trim)
+	if err == errs.ErrNotFound {
+		return e.sr.GetNil()
+	}
+
+	if err != nil {
+		return e.sr.GetErrorBytes(err.Error())
+	}
+
+	// log the generated ID so that an AOF replay rebuilds the same stream
+	cmd.Arguments[i] = id.String()
+
+	return e.sr.GetBulkStringBytes(id.String())
+}
+
+// parseStreamTrim reads MAXLEN|MINID [=|~] threshold [LIMIT count] and reports
+// how many arguments it consumed
+func parseStreamTrim(args []string) (objects.StreamTrim, int, error) {
+	trim := objects.StreamTrim{}
+	if len(args) < 2 {
+		return trim, 0, errs.SyntaxError
+	}
+
+	trim.Strategy = objects.StreamTrimStrategy(strings.ToUpper(args[0]))
+	if trim.Strategy != objects.TrimMaxLen && trim.Strategy != objects.TrimMinID {
+		return trim, 0, errs.SyntaxError
+	}
+
+	i := 1
+	if args[i] == "=" || args[i] == "~" {
+		trim.Approximate = args[i] == "~"
+		i++
+	}
+
+	if i >= len(args) {
+		return trim, 0, errs.SyntaxError
+	}
+
+	if trim.Strategy == objects.TrimMaxLen {
+		maxLen, err := strconv.Atoi(args[i])
+		if err != nil || maxLen < 0 {
+			return trim, 0, errs.NotAnInteger
+		}
+		trim.MaxLen = maxLen
+	} else {
+		minID, err := objects.ParseStreamID(args[i], 0)
+		if err != nil {
+			return trim, 0, err
+		}
+		trim.MinID = minID
+	}
+	i++
+
+	if i+1 < len(args) && strings.ToUpper(args[i]) == "LIMIT" {
+		if !trim.Approximate {
+			return trim, 0, errs.SyntaxError
+		}
+
+		limit, err := strconv.Atoi(args[i+1])
+		if err != nil || limit < 0 {
+			return trim, 0, errs.NotAnInteger
+		}
+
+		trim.Limit = limit
+		i += 2
+	}
+
+	return trim, i, nil
+}
+
+// XRANGE key start end [COUNT count], XREVRANGE key end start [COUNT count]
+func (e *Executor) xrange(cmd *commands.RedisCommand) []byte {
+	startArg, endArg := cmd.Arguments[1], cmd.Arguments[2]
+	reverse := cmd.Action == actions.XRevRange
+	if reverse {
+		startArg, endArg = endArg, startArg
+	}
+
+	start, err := objects.ParseStreamRangeStart(startArg)
+	if err != nil {
+		return e.sr.GetErrorBytes(err.Error())
+	}
+
+	end, err := objects.ParseStreamRangeEnd(endArg)
+	if err != nil {
+		return e.sr.GetErrorBytes(err.Error())
+	}
+
+	count := 0
+	if len(cmd.Arguments) == 5 {
+		if strings.ToUpper(cmd.Arguments[3]) != "COUNT" {
+			return e.sr.GetErrorBytes(errs.SyntaxError.Error())
+		}
+
+		count, err = strconv.Atoi(cmd.Arguments[4])
+		if err != nil {
+			return e.sr.GetErrorBytes(errs.NotAnInteger.Error())
+		}
+
+		// an explicit COUNT 0 returns nothing
+		if count <= 0 {
+			return e.sr.GetArrayOfBulkStringBytes([]string{})
+		}
+	} else if len(cmd.Arguments) != 3 {
+		return e.sr.GetErrorBytes(errs.SyntaxError.Error())
+	}
+
+	entries, err := e.store.XRange(cmd.Arguments[0], start, end, count, reverse)
+	if err == errs.ErrNotFound {
+		return e.sr.GetArrayOfBulkStringBytes([]string{})
+	}
+
+	if err != nil {
+		return e.sr.GetErrorBytes(err.Error())
+	}
+
+	return e.streamEntriesBytes(entries)
+}
+
+// XREAD [COUNT count] [BLOCK milliseconds] STREAMS key [key ...] id [id ...]
+func (e *Executor) xread(cmd *commands.RedisCommand, client *clients.Client) []byte {
+	count := 0
+	block := false
+	var timeout time.Duration
+
+	i := 0
+	for ; i < len(cmd.Arguments); i++ {
+		option := strings.ToUpper(cmd.Arguments[i])
+		if option == "STREAMS" {
+			break
+		}
+
+		if i+1 >= len(cmd.Arguments) {
+			return e.sr.GetErrorBytes(errs.SyntaxError.Error())
+		}
+
+		switch option {
+		case "COUNT":
+			n, err := strconv.Atoi(cmd.Arguments[i+1])
+			if err != nil {
+				return e.sr.GetErrorBytes(errs.NotAnInteger.Error())
+			}
+			count = n
+
+		case "BLOCK":
+			ms, err := strconv.Atoi(cmd.Arguments[i+1])
+			if err != nil {
+				return e.sr.GetErrorBytes("TIMEOUT IS NOT AN INTEGER OR OUT OF RANGE")
+			}
+			if ms < 0 {
+				return e.sr.GetErrorBytes("TIMEOUT IS NEGATIVE")
+			}
+
+			block = true
+			timeout = time.Duration(ms) * time.Millisecond
+
+		default:
+			return e.sr.GetErrorBytes(errs.SyntaxError.Error())
+		}
+		i++
+	}
+
+	streams := cmd.Arguments[min(i+1, len(cmd.Arguments)):]
+	if i >= len(cmd.Arguments) || len(streams) == 0 || len(streams)%2 != 0 {
+		return e.sr.GetErrorBytes("UNBALANCED XREAD LIST OF STREAMS: FOR EACH STREAM KEY AN ID OR '$' MUST BE SPECIFIED")
+	}
+
+	keys, ids := streams[:len(streams)/2], streams[len(streams)/2:]
+
+	ctx, release := client.Block()
+	defer release()
+
+	results, err := e.store.XRead(ctx, keys, ids, count, block, timeout)
+	if err == errs.Timeout {
+		return e.sr.GetNilArray()
+	}
+
+	if err != nil {
+		return e.sr.GetErrorBytes(err.Error())
+	}
+
+	if len(results) == 0 {
+		return e.sr.GetNilArray()
+	}
+
+	return e.streamReadResultsBytes(results)
+}
+
+func (e *Executor) streamEntriesBytes(entries []objects.StreamEntry) []byte {
+	items := make([][]byte, len(entries))
+
+	for i, entry := range entries {
+		// entries deleted while pending in a consumer group have no fields
+		fields := e.sr.GetNilArray()
+		if entry.Fields != nil {
+			fields = e.sr.GetArrayOfBulkStringBytes(entry.Fields)
+		}
+
+		items[i] = e.sr.GetArrayBytes([][]byte{
+			e.sr.GetBulkStringBytes(entry.ID.String()),
+			fields,
+		})
+	}
+
+	return e.sr.GetArrayBytes(items)
+}
+
+func (e *Executor) streamReadResultsBytes(results []store.StreamReadResult) []byte {
+	items := make([][]byte, len(results))
+
+	for i, result := range results {
+		items[i] = e.sr.GetArrayBytes([][]byte{
+			e.sr.GetBulkStringBytes(result.Key),
+			e.streamEntriesBytes(result.Entries),
+		})
+	}
+
+	return e.sr.GetArrayBytes(items)
+}
@@ -0,0 +1,523 @@
+package executor
+
+import (
+	"server/clients"
+	"server/commands"
+	"server/errs"
+	"server/store/actions"
+	"server/store/objects"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ———————————————————————————————————————————————————————————————
+// Stream consumer group commands
+// ———————————————————————————————————————————————————————————————
+
+func (e *Executor) executeStreamGroupCommand(cmd *commands.RedisCommand, client *clients.Client) []byte {
+	switch cmd.Action {
+	case actions.XGroup:
+		return e.xgroup(cmd)
+
+	case actions.XReadGroup:
+		return e.xreadgroup(cmd, client)
+
+	case actions.XAck:
+		ids, err := parseStreamIDs(cmd.Arguments[2:])
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		cnt, err := e.store.XAck(cmd.Arguments[0], cmd.Arguments[1], ids)
+		if err == errs.NoGroup {
+			return e.sr.GetIntegerBytes(0)
+		}
+
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		return e.sr.GetIntegerBytes(cnt)
+
+	case actions.XPending:
+		return e.xpending(cmd)
+
+	case actions.XClaim:
+		return e.xclaim(cmd)
+
+	case actions.XAutoClaim:
+		return e.xautoclaim(cmd)
+
+	default:
+		return e.sr.GetErrorBytes("ERR unknown command")
+	}
+}
+
+func parseStreamIDs(args []string) ([]objects.StreamID, error) {
+	ids := make([]objects.StreamID, len(args))
+	for i, arg := range args {
+		id, err := objects.ParseStreamID(arg, 0)
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = id
+	}
+
+	return ids, nil
+}
+
+func parseMinIdle(s string) (time.Duration, error) {
+	ms, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || ms < 0 {
+		return 0, errs.NotAnInteger
+	}
+
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// XGROUP CREATE key group id|$ [MKSTREAM] [ENTRIESREAD n]
+// XGROUP SETID key group id|$ [ENTRIESREAD n]
+// XGROUP DESTROY key group
+// XGROUP CREATECONSUMER key group consumer
+// XGROUP DELCONSUMER key group consumer
+func (e *Executor) xgroup(cmd *commands.RedisCommand) []byte {
+	subcommand := strings.ToUpper(cmd.Arguments[0])
+	args := cmd.Arguments[1:]
+
+	switch subcommand {
+	case "CREATE", "SETID":
+		if len(args) < 3 {
+			return e.sr.GetErrorBytes(errs.IncorrectNumberOfArguments.Error())
+		}
+
+		mkStream := false
+		for i := 3; i < len(args); i++ {
+			switch strings.ToUpper(args[i]) {
+			case "MKSTREAM":
+				if subcommand != "CREATE" {
+					return e.sr.GetErrorBytes(errs.SyntaxError.Error())
+				}
+				mkStream = true
+			case "ENTRIESREAD":
+				// accepted for compatibility, lag is not tracked
+				i++
+				if i >= len(args) {
+					return e.sr.GetErrorBytes(errs.SyntaxError.Error())
+				}
+			default:
+				return e.sr.GetErrorBytes(errs.SyntaxError.Error())
+			}
+		}
+
+		var err error
+		if subcommand == "CREATE" {
+			err = e.store.XGroupCreate(args[0], args[1], args[2], mkStream)
+		} else {
+			err = e.store.XGroupSetID(args[0], args[1], args[2])
+		}
+
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		return e.sr.GetSimpleStringBytes("OK")
+
+	case "DESTROY":
+		if len(args) != 2 {
+			return e.sr.GetErrorBytes(errs.IncorrectNumberOfArguments.Error())
+		}
+
+		cnt, err := e.store.XGroupDestroy(args[0], args[1])
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		return e.sr.GetIntegerBytes(cnt)
+
+	case "CREATECONSUMER", "DELCONSUMER":
+		if len(args) != 3 {
+			return e.sr.GetErrorBytes(errs.IncorrectNumberOfArguments.Error())
+		}
+
+		var cnt int
+		var err error
+		if subcommand == "CREATECONSUMER" {
+			cnt, err = e.store.XGroupCreateConsumer(args[0], args[1], args[2])
+		} else {
+			cnt, err = e.store.XGroupDelConsumer(args[0], args[1], args[2])
+		}
+
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		return e.sr.GetIntegerBytes(cnt)
+
+	default:
+		return e.sr.GetErrorBytes(errs.SyntaxError.Error())
+	}
+}
+
+// XREADGROUP GROUP group consumer [COUNT count] [BLOCK milliseconds] [NOACK] STREAMS key [key ...] id [id ...]
+func (e *Executor) xreadgroup(cmd *commands.RedisCommand, client *clients.Client) []byte {
+	if strings.ToUpper(cmd.Arguments[0]) != "GROUP" {
+		return e.sr.GetErrorBytes(errs.SyntaxError.Error())
+	}
+
+	group, consumer := cmd.Arguments[1], cmd.Arguments[2]
+
+	count := 0
+	block, noAck := false, false
+	var timeout time.Duration
+
+	// the AOF gets the same read without BLOCK so that a replay never waits
+	propagated := []string{cmd.Arguments[0], group, consumer}
+
+	i := 3
+	for ; i < len(cmd.Arguments); i++ {
+		option := strings.ToUpper(cmd.Arguments[i])
+		if option == "STREAMS" {
+			break
+		}
+
+		if option == "NOACK" {
+			noAck = true
+			propagated = append(propagated, cmd.Arguments[i])
+			continue
+		}
+
+		if i+1 >= len(cmd.Arguments) {
+			return e.sr.GetErrorBytes(errs.SyntaxError.Error())
+		}
+
+		switch option {
+		case "COUNT":
+			n, err := strconv.Atoi(cmd.Arguments[i+1])
+			if err != nil {
+				return e.sr.GetErrorBytes(errs.NotAnInteger.Error())
+			}
+			count = n
+			propagated = append(propagated, cmd.Arguments[i], cmd.Arguments[i+1])
+
+		case "BLOCK":
+			ms, err := strconv.Atoi(cmd.Arguments[i+1])
+			if err != nil {
+				return e.sr.GetErrorBytes("TIMEOUT IS NOT AN INTEGER OR OUT OF RANGE")
+			}
+			if ms < 0 {
+				return e.sr.GetErrorBytes("TIMEOUT IS NEGATIVE")
+			}
+
+			block = true
+			timeout = time.Duration(ms) * time.Millisecond
+
+		default:
+			return e.sr.GetErrorBytes(errs.SyntaxError.Error())
+		}
+		i++
+	}
+
+	streams := cmd.Arguments[min(i+1, len(cmd.Arguments)):]
+	if i >= len(cmd.Arguments) || len(streams) == 0 || len(streams)%2 != 0 {
+		return e.sr.GetErrorBytes("UNBALANCED XREADGROUP LIST OF STREAMS: FOR EACH STREAM KEY AN ID OR '>' MUST BE SPECIFIED")
+	}
+
+	keys, ids := streams[:len(streams)/2], streams[len(streams)/2:]
+
+	ctx, release := client.Block()
+	defer release()
+
+	results, err := e.store.XReadGroup(ctx, group, consumer, keys, ids, count, noAck, block, timeout)
+	if err == errs.Timeout {
+		return e.sr.GetNilArray()
+	}
+
+	if err != nil {
+		return e.sr.GetErrorBytes(err.Error())
+	}
+
+	cmd.Arguments = append(propagated, cmd.Arguments[i:]...)
+
+	if len(results) == 0 {
+		return e.sr.GetNilArray()
+	}
+
+	return e.streamReadResultsBytes(results)
+}
+
+// XPENDING key group [[IDLE min-idle-time] start end count [consumer]]
+func (e *Executor) xpending(cmd *commands.RedisCommand) []byte {
+	key, group := cmd.Arguments[0], cmd.Arguments[1]
+
+	if len(cmd.Arguments) == 2 {
+		summary, err := e.store.XPendingSummary(key, group)
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		if summary.Count == 0 {
+			return e.sr.GetArrayBytes([][]byte{
+				e.sr.GetIntegerBytes(0),
+				e.sr.GetNil(),
+				e.sr.GetNil(),
+				e.sr.GetNilArray(),
+			})
+		}
+
+		consumers := make([][]byte, len(summary.Consumers))
+		for i, consumer := range summary.Consumers {
+			consumers[i] = e.sr.GetArrayOfBulkStringBytes([]string{consumer.Consumer, strconv.Itoa(consumer.Count)})
+		}
+
+		return e.sr.GetArrayBytes([][]byte{
+			e.sr.GetIntegerBytes(summary.Count),
+			e.sr.GetBulkStringBytes(summary.MinID.String()),
+			e.sr.GetBulkStringBytes(summary.MaxID.String()),
+			e.sr.GetArrayBytes(consumers),
+		})
+	}
+
+	args := cmd.Arguments[2:]
+	query := objects.StreamPendingQuery{}
+
+	if strings.ToUpper(args[0]) == "IDLE" {
+		if len(args) < 2 {
+			return e.sr.GetErrorBytes(errs.SyntaxError.Error())
+		}
+
+		minIdle, err := parseMinIdle(args[1])
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		query.MinIdle = minIdle
+		args = args[2:]
+	}
+
+	if len(args) != 3 && len(args) != 4 {
+		return e.sr.GetErrorBytes(errs.SyntaxError.Error())
+	}
+
+	var err error
+	if query.Start, err = objects.ParseStreamRangeStart(args[0]); err != nil {
+		return e.sr.GetErrorBytes(err.Error())
+	}
+
+	if query.End, err = objects.ParseStreamRangeEnd(args[1]); err != nil {
+		return e.sr.GetErrorBytes(err.Error())
+	}
+
+	if query.Count, err = strconv.Atoi(args[2]); err != nil {
+		return e.sr.GetErrorBytes(errs.NotAnInteger.Error())
+	}
+
+	if len(args) == 4 {
+		query.Consumer = args[3]
+	}
+
+	pending, err := e.store.XPendingRange(key, group, query)
+	if err != nil {
+		return e.sr.GetErrorBytes(err.Error())
+	}
+
+	now := time.Now()
+	items := make([][]byte, len(pending))
+	for i, pe := range pending {
+		items[i] = e.sr.GetArrayBytes([][]byte{
+			e.sr.GetBulkStringBytes(pe.ID.String()),
+			e.sr.GetBulkStringBytes(pe.Consumer),
+			e.sr.GetIntegerBytes(int(pe.Idle(now).Milliseconds())),
+			e.sr.GetIntegerBytes(pe.DeliveryCount),
+		})
+	}
+
+	return e.sr.GetArrayBytes(items)
+}
+
+// XCLAIM key group consumer min-idle-time id [id ...] [IDLE ms] [TIME unix-time-ms]
+// [RETRYCOUNT count] [FORCE] [JUSTID] [LASTID lastid]
+func (e *Executor) xclaim(cmd *commands.RedisCommand) []byte {
+	key, group, consumer := cmd.Arguments[0], cmd.Arguments[1], cmd.Arguments[2]
+
+	minIdle, err := parseMinIdle(cmd.Arguments[3])
+	if err != nil {
+		return e.sr.GetErrorBytes(err.Error())
+	}
+
+	// IDs run until the first option
+	i := 4
+	ids := []objects.StreamID{}
+	for ; i < len(cmd.Arguments); i++ {
+		id, err := objects.ParseStreamID(cmd.Arguments[i], 0)
+		if err != nil {
+			break
+		}
+		ids = append(ids, id)
+	}
+
+	if len(ids) == 0 {
+		return e.sr.GetErrorBytes(errs.InvalidStreamID.Error())
+	}
+
+	optionArgs := cmd.Arguments[i:]
+	options := objects.StreamClaimOptions{}
+
+	for ; i < len(cmd.Arguments); i++ {
+		option := strings.ToUpper(cmd.Arguments[i])
+
+		switch option {
+		case "FORCE":
+			options.Force = true
+			continue
+		case "JUSTID":
+			options.JustID = true
+			continue
+		}
+
+		if i+1 >= len(cmd.Arguments) {
+			return e.sr.GetErrorBytes(errs.SyntaxError.Error())
+		}
+		value := cmd.Arguments[i+1]
+		i++
+
+		switch option {
+		case "IDLE":
+			idle, err := parseMinIdle(value)
+			if err != nil {
+				return e.sr.GetErrorBytes(err.Error())
+			}
+			options.Idle = &idle
+
+		case "TIME":
+			ms, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return e.sr.GetErrorBytes(errs.NotAnInteger.Error())
+			}
+			at := time.UnixMilli(ms)
+			options.Time = &at
+
+		case "RETRYCOUNT":
+			retryCount, err := strconv.Atoi(value)
+			if err != nil || retryCount < 0 {
+				return e.sr.GetErrorBytes(errs.NotAnInteger.Error())
+			}
+			options.RetryCount = &retryCount
+
+		case "LASTID":
+			lastID, err := objects.ParseStreamID(value, 0)
+			if err != nil {
+				return e.sr.GetErrorBytes(err.Error())
+			}
+			options.LastID = &lastID
+
+		default:
+			return e.sr.GetErrorBytes(errs.SyntaxError.Error())
+		}
+	}
+
+	claimed, deleted, err := e.store.XClaim(key, group, consumer, minIdle, ids, options)
+	if err != nil {
+		return e.sr.GetErrorBytes(err.Error())
+	}
+
+	e.propagateClaim(cmd, claimed, deleted, optionArgs)
+
+	if options.JustID {
+		return e.sr.GetArrayOfBulkStringBytes(streamEntryIDs(claimed))
+	}
+
+	return e.streamEntriesBytes(claimed)
+}
+
+// XAUTOCLAIM key group consumer min-idle-time start [COUNT count] [JUSTID]
+func (e *Executor) xautoclaim(cmd *commands.RedisCommand) []byte {
+	key, group, consumer := cmd.Arguments[0], cmd.Arguments[1], cmd.Arguments[2]
+
+	minIdle, err := parseMinIdle(cmd.Arguments[3])
+	if err != nil {
+		return e.sr.GetErrorBytes(err.Error())
+	}
+
+	start, err := objects.ParseStreamRangeStart(cmd.Arguments[4])
+	if err != nil {
+		return e.sr.GetErrorBytes(err.Error())
+	}
+
+	count := 100
+	justID := false
+	optionArgs := []string{}
+
+	for i := 5; i < len(cmd.Arguments); i++ {
+		switch strings.ToUpper(cmd.Arguments[i]) {
+		case "COUNT":
+			if i+1 >= len(cmd.Arguments) {
+				return e.sr.GetErrorBytes(errs.SyntaxError.Error())
+			}
+
+			count, err = strconv.Atoi(cmd.Arguments[i+1])
+			if err != nil || count < 1 {
+				return e.sr.GetErrorBytes("COUNT MUST BE > 0")
+			}
+			i++
+
+		case "JUSTID":
+			justID = true
+			optionArgs = append(optionArgs, cmd.Arguments[i])
+
+		default:
+			return e.sr.GetErrorBytes(errs.SyntaxError.Error())
+		}
+	}
+
+	next, claimed, deleted, err := e.store.XAutoClaim(key, group, consumer, minIdle, start, count, justID)
+	if err != nil {
+		return e.sr.GetErrorBytes(err.Error())
+	}
+
+	e.propagateClaim(cmd, claimed, deleted, optionArgs)
+
+	claimedBytes := e.streamEntriesBytes(claimed)
+	if justID {
+		claimedBytes = e.sr.GetArrayOfBulkStringBytes(streamEntryIDs(claimed))
+	}
+
+	deletedIDs := make([]string, len(deleted))
+	for i, id := range deleted {
+		deletedIDs[i] = id.String()
+	}
+
+	return e.sr.GetArrayBytes([][]byte{
+		e.sr.GetBulkStringBytes(next.String()),
+		claimedBytes,
+		e.sr.GetArrayOfBulkStringBytes(deletedIDs),
+	})
+}
+
+// propagateClaim rewrites a claim for the AOF as an XCLAIM of exactly the IDs it touched
+// with no idle requirement, since idle times are not reproducible on replay
+func (e *Executor) propagateClaim(cmd *commands.RedisCommand, claimed []objects.StreamEntry, deleted []objects.StreamID, optionArgs []string) {
+	if len(claimed) == 0 && len(deleted) == 0 {
+		return
+	}
+
+	args := []string{cmd.Arguments[0], cmd.Arguments[1], cmd.Arguments[2], "0"}
+	for _, entry := range claimed {
+		args = append(args, entry.ID.String())
+	}
+	for _, id := range deleted {
+		args = append(args, id.String())
+	}
+
+	cmd.Action = actions.XClaim
+	cmd.Arguments = append(args, optionArgs...)
+}
+
+func streamEntryIDs(entries []objects.StreamEntry) []string {
+	ids := make([]string, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.ID.String()
+	}
+
+	return ids
+}
@@ -0,0 +1,300 @@
+package executor
+
+import (
+	"math"
+	"server/commands"
+	"server/errs"
+	"server/store"
+	"server/store/actions"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ———————————————————————————————————————————————————————————————
+// String commands
+// ———————————————————————————————————————————————————————————————
+
+// SET key value [NX|XX] [GET] [EX seconds|PX milliseconds|EXAT unix-time-seconds|PXAT unix-time-milliseconds|KEEPTTL]
+func (e *Executor) set(cmd *commands.RedisCommand) []byte {
+	options := store.SetOptions{}
+	expiryOption := ""
+
+	for i := 2; i < len(cmd.Arguments); i++ {
+		option := strings.ToUpper(cmd.Arguments[i])
+
+		switch option {
+		case "NX":
+			options.NX = true
+		case "XX":
+			options.XX = true
+		case "GET":
+			options.Get = true
+		case "KEEPTTL":
+			options.KeepTTL = true
+
+		case "EX", "PX", "EXAT", "PXAT":
+			if expiryOption != "" || i+1 >= len(cmd.Arguments) {
+				return e.sr.GetErrorBytes(errs.SyntaxError.Error())
+			}
+
+			at, err := parseExpiryTime(option, cmd.Arguments[i+1])
+			if err != nil {
+				return e.sr.GetErrorBytes("INVALID EXPIRE TIME IN 'SET' COMMAND")
+			}
+
+			expiryOption = option
+			options.ExpireAt = &at
+			i++
+
+		default:
+			return e.sr.GetErrorBytes(errs.SyntaxError.Error())
+		}
+	}
+
+	if (options.NX && options.XX) || (options.KeepTTL && expiryOption != "") {
+		return e.sr.GetErrorBytes(errs.SyntaxError.Error())
+	}
+
+	result, err := e.store.Set(cmd.Arguments[0], cmd.Arguments[1], options)
+	if err != nil {
+		return e.sr.GetErrorBytes(err.Error())
+	}
+
+	// relative expiries are logged as absolute ones so that an AOF replay keeps the deadline
+	if result.Written && options.ExpireAt != nil {
+		cmd.Arguments = []string{
+			cmd.Arguments[0],
+			cmd.Arguments[1],
+			"PXAT",
+			strconv.FormatInt(options.ExpireAt.UnixMilli(), 10),
+		}
+	}
+
+	if options.Get {
+		if !result.Existed {
+			return e.sr.GetNil()
+		}
+		return e.sr.GetBulkStringBytes(result.Previous)
+	}
+
+	if !result.Written {
+		return e.sr.GetNil()
+	}
+
+	return e.sr.GetSimpleStringBytes("OK")
+}
+
+// parseExpiryTime turns an EX, PX, EXAT or PXAT argument into an absolute time,
+// the value has to be a positive integer
+func parseExpiryTime(unit string, value string) (time.Time, error) {
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n <= 0 {
+		return time.Time{}, errs.NotAnInteger
+	}
+
+	switch unit {
+	case "EX":
+		if n > math.MaxInt64/int64(time.Second) {
+			return time.Time{}, errs.NotAnInteger
+		}
+		return time.Now().Add(time.Duration(n) * time.Second), nil
+	case "PX":
+		if n > math.MaxInt64/int64(time.Millisecond) {
+			return time.Time{}, errs.NotAnInteger
+		}
+		return time.Now().Add(time.Duration(n) * time.Millisecond), nil
+	case "EXAT":
+		return time.Unix(n, 0), nil
+	default:
+		return time.UnixMilli(n), nil
+	}
+}
+
+// GETEX key [EX seconds|PX milliseconds|EXAT unix-time-seconds|PXAT unix-time-milliseconds|PERSIST]
+func (e *Executor) getex(cmd *commands.RedisCommand) []byte {
+	options := store.GetExOptions{}
+
+	switch {
+	case len(cmd.Arguments) == 1:
+		;
+	case len(cmd.Arguments) == 2 && strings.ToUpper(cmd.Arguments[1]) == "PERSIST":
+		options.Persist = true
+	case len(cmd.Arguments) == 3:
+		option := strings.ToUpper(cmd.Arguments[1])
+		if option != "EX" && option != "PX" && option != "EXAT" && option != "PXAT" {
+			return e.sr.GetErrorBytes(errs.SyntaxError.Error())
+		}
+
+		at, err := parseExpiryTime(option, cmd.Arguments[2])
+		if err != nil {
+			return e.sr.GetErrorBytes("INVALID EXPIRE TIME IN 'GETEX' COMMAND")
+		}
+		options.ExpireAt = &at
+	default:
+		return e.sr.GetErrorBytes(errs.SyntaxError.Error())
+	}
+
+	value, err := e.store.GetEx(cmd.Arguments[0], options)
+	if err == errs.ErrNotFound {
+		return e.sr.GetNil()
+	}
+
+	if err != nil {
+		return e.sr.GetErrorBytes(err.Error())
+	}
+
+	// logged with the absolute expiry, like SET
+	if options.ExpireAt != nil {
+		cmd.Arguments = []string{cmd.Arguments[0], "PXAT", strconv.FormatInt(options.ExpireAt.UnixMilli(), 10)}
+	}
+
+	return e.sr.GetBulkStringBytes(value)
+}
+
+func (e *Executor) executeStringCommand(cmd *commands.RedisCommand) []byte {
+	switch cmd.Action {
+	case actions.Incr, actions.Decr, actions.IncrBy, actions.DecrBy:
+		delta := int64(1)
+
+		if cmd.Action == actions.IncrBy || cmd.Action == actions.DecrBy {
+			var err error
+			delta, err = strconv.ParseInt(cmd.Arguments[1], 10, 64)
+			if err != nil {
+				return e.sr.GetErrorBytes(errs.NotAnInteger.Error())
+			}
+		}
+
+		if cmd.Action == actions.Decr || cmd.Action == actions.DecrBy {
+			if delta == math.MinInt64 {
+				return e.sr.GetErrorBytes(errs.IncrOverflow.Error())
+			}
+			delta = -delta
+		}
+
+		value, err := e.store.IncrBy(cmd.Arguments[0], delta)
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		return e.sr.GetIntegerBytes(int(value))
+
+	case actions.IncrByFloat:
+		delta, err := strconv.ParseFloat(cmd.Arguments[1], 64)
+		if err != nil || math.IsNaN(delta) || math.IsInf(delta, 0) {
+			return e.sr.GetErrorBytes(errs.NotAFloat.Error())
+		}
+
+		value, err := e.store.IncrByFloat(cmd.Arguments[0], delta)
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		return e.sr.GetBulkStringBytes(store.FormatFloat(value))
+
+	case actions.Append:
+		length, err := e.store.Append(cmd.Arguments[0], cmd.Arguments[1])
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		return e.sr.GetIntegerBytes(length)
+
+	case actions.StrLen:
+		length, err := e.store.StrLen(cmd.Arguments[0])
+		if err == errs.ErrNotFound {
+			return e.sr.GetIntegerBytes(0)
+		}
+
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		return e.sr.GetIntegerBytes(length)
+
+	case actions.GetRange:
+		start, startErr := strconv.Atoi(cmd.Arguments[1])
+		end, endErr := strconv.Atoi(cmd.Arguments[2])
+		if startErr != nil || endErr != nil {
+			return e.sr.GetErrorBytes(errs.NotAnInteger.Error())
+		}
+
+		value, err := e.store.GetRange(cmd.Arguments[0], start, end)
+		if err == errs.ErrNotFound {
+			return e.sr.GetBulkStringBytes("")
+		}
+
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		return e.sr.GetBulkStringBytes(value)
+
+	case actions.SetRange:
+		offset, err := strconv.Atoi(cmd.Arguments[1])
+		if err != nil {
+			return e.sr.GetErrorBytes(errs.NotAnInteger.Error())
+		}
+
+		if offset < 0 {
+			return e.sr.GetErrorBytes(errs.OffsetOutOfRange.Error())
+		}
+
+		length, err := e.store.SetRange(cmd.Arguments[0], offset, cmd.Arguments[2])
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		return e.sr.GetIntegerBytes(length)
+
+	case actions.MGet:
+		values := e.store.MGet(cmd.Arguments)
+
+		items := make([][]byte, len(values))
+		for i, value := range values {
+			if value == nil {
+				items[i] = e.sr.GetNil()
+			} else {
+				items[i] = e.sr.GetBulkStringBytes(*value)
+			}
+		}
+
+		return e.sr.GetArrayBytes(items)
+
+	case actions.MSet:
+		e.store.MSet(cmd.Arguments)
+		return e.sr.GetSimpleStringBytes("OK")
+
+	case actions.MSetNX:
+		if e.store.MSetNX(cmd.Arguments) {
+			return e.sr.GetIntegerBytes(1)
+		}
+		return e.sr.GetIntegerBytes(0)
+
+	case actions.GetDel, actions.GetSet:
+		var value string
+		var err error
+
+		if cmd.Action == actions.GetDel {
+			value, err = e.store.GetDel(cmd.Arguments[0])
+		} else {
+			value, err = e.store.GetSet(cmd.Arguments[0], cmd.Arguments[1])
+		}
+
+		if err == errs.ErrNotFound {
+			return e.sr.GetNil()
+		}
+
+		if err != nil {
+			return e.sr.GetErrorBytes(err.Error())
+		}
+
+		return e.sr.GetBulkStringBytes(value)
+
+	case actions.GetEx:
+		return e.getex(cmd)
+
+	default:
+		return e.sr.GetErrorBytes("ERR unknown command")
+	}
+}
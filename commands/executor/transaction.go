@@ -0,0 +1,95 @@
+package executor
+
+import (
+	"server/clients"
+	"server/commands"
+	"server/store"
+	"server/store/actions"
+)
+
+// ———————————————————————————————————————————————————————————————
+// Transactions
+// ———————————————————————————————————————————————————————————————
+
+// isTransactionCommand reports whether the action runs right away inside MULTI instead of being queued
+func isTransactionCommand(action actions.Action) bool {
+	return action == actions.Multi || action == actions.Exec || action == actions.Discard || action == actions.Watch ||
+		action == actions.Quit
+}
+
+func (e *Executor) executeTransactionCommand(cmd *commands.RedisCommand, client *clients.Client) []byte {
+	switch cmd.Action {
+	case actions.Multi:
+		if !client.Multi() {
+			return e.sr.GetErrorBytes("MULTI CALLS CAN NOT BE NESTED")
+		}
+		return e.sr.GetSimpleStringBytes("OK")
+
+	case actions.Discard:
+		if !client.InTransaction() {
+			return e.sr.GetErrorBytes("DISCARD WITHOUT MULTI")
+		}
+
+		client.EndTransaction()
+		store.Unwatch(e.databases, client.Watch())
+		return e.sr.GetSimpleStringBytes("OK")
+
+	case actions.Watch:
+		if client.InTransaction() {
+			return e.sr.GetErrorBytes("WATCH INSIDE MULTI IS NOT ALLOWED")
+		}
+
+		e.store.Watch(client.Watch(), cmd.Arguments)
+		return e.sr.GetSimpleStringBytes("OK")
+
+	case actions.Unwatch:
+		store.Unwatch(e.databases, client.Watch())
+		return e.sr.GetSimpleStringBytes("OK")
+
+	case actions.Exec:
+		if !client.InTransaction() {
+			return e.sr.GetErrorBytes("EXEC WITHOUT MULTI")
+		}
+
+		queued, ok := client.EndTransaction()
+		if !ok {
+			store.Unwatch(e.databases, client.Watch())
+			return e.sr.GetErrorBytes("EXECABORT TRANSACTION DISCARDED BECAUSE OF PREVIOUS ERRORS")
+		}
+
+		return e.exec(cmd, queued, client)
+	}
+
+	return nil
+}
+
+// exec runs the queued commands back to back, no other client runs a command in between.
+// The mutations that succeeded are kept on the EXEC command for the AOF. Nothing runs and
+// the reply is nil when a watched key was modified.
+func (e *Executor) exec(cmd *commands.RedisCommand, queued []*commands.RedisCommand, client *clients.Client) []byte {
+	replies := make([][]byte, len(queued))
+	cmd.Transaction = nil
+
+	ran := store.Transaction(e.databases, client.Watch(), func(views []*store.Store) {
+		tx := NewExecutor(views, e.hub, e.notifier)
+
+		client.Exec(func() {
+			for i, queuedCmd := range queued {
+				replies[i] = tx.ExecuteCommand(queuedCmd, client)
+				if replies[i] == nil {
+					replies[i] = e.sr.GetErrorBytes("ERR COULD NOT EXECUTE COMMAND")
+				}
+
+				if _, ok := actions.MutationCommands[queuedCmd.Action]; ok && replies[i][0] != '-' {
+					cmd.Transaction = append(cmd.Transaction, queuedCmd)
+				}
+			}
+		})
+	})
+
+	if !ran {
+		return e.sr.GetNilArray()
+	}
+
+	return e.sr.GetArrayBytes(replies)
+}
@@ -20,6 +20,10 @@ func (sr *Serializer) GetNil() []byte {
 	return []byte("$-1\r\n")
 }
 
+func (sr *Serializer) GetNilArray() []byte {
+	return []byte("*-1\r\n")
+}
+
 func (sr *Serializer) GetErrorBytes(s string) []byte {
 	size := 1 + len(s) + 2
 	buf := make([]byte, 0, size)
@@ -98,6 +102,42 @@ func (sr *Serializer) GetArrayOfBulkStringBytes(items []string) []byte {
 	return buf
 }
 
+// GetArrayBytes wraps already serialized items into an array, used for nested replies
+func (sr *Serializer) GetArrayBytes(items [][]byte) []byte {
+	capEst := 1 + 20 + 2
+
+	for _, item := range items {
+		capEst += len(item)
+	}
+
+	buf := make([]byte, 0, capEst)
+
+	buf = append(buf, '*')
+	buf = strconv.AppendInt(buf, int64(len(items)), 10)
+	buf = append(buf, '\r', '\n')
+
+	for _, item := range items {
+		buf = append(buf, item...)
+	}
+
+	return buf
+}
+
+// GetMessageBytes is the frame pushed to the subscribers of a channel
+func (sr *Serializer) GetMessageBytes(channel, message string) []byte {
+	return sr.GetArrayOfBulkStringBytes([]string{"message", channel, message})
+}
+
+// GetPatternMessageBytes is the frame pushed to the subscribers of a pattern matching the channel
+func (sr *Serializer) GetPatternMessageBytes(pattern, channel, message string) []byte {
+	return sr.GetArrayOfBulkStringBytes([]string{"pmessage", pattern, channel, message})
+}
+
+// GetShardMessageBytes is the frame pushed to the subscribers of a shard channel
+func (sr *Serializer) GetShardMessageBytes(channel, message string) []byte {
+	return sr.GetArrayOfBulkStringBytes([]string{"smessage", channel, message})
+}
+
 func (sr *Serializer) getBulkStringBytesSize(s string) int {
 	// $ length(20 decimal places) \r\n len(s) \r\n
 	return 1+ 20 + 2 + len(s) + 2
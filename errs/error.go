@@ -7,4 +7,31 @@ var InvalidDataType = errors.New("INVALID DATA TYPE")
 var IncorrectNumberOfArguments = errors.New("INCORRECT NUMBER OF ARGUMENTS")
 var InvalidCommand = errors.New("INVALID COMMAND")
 var InvalidMethod = errors.New("INVALID METHOD")
-var TypeMismatch = errors.New("TYPE MISMATCH")
\ No newline at end of file
+var TypeMismatch = errors.New("TYPE MISMATCH")
+var SyntaxError = errors.New("SYNTAX ERROR")
+var NotAFloat = errors.New("VALUE IS NOT A VALID FLOAT")
+var NotAnInteger = errors.New("VALUE IS NOT AN INTEGER OR OUT OF RANGE")
+var InvalidScoreRange = errors.New("MIN OR MAX IS NOT A FLOAT")
+var InvalidLexRange = errors.New("MIN OR MAX NOT VALID STRING RANGE ITEM")
+var ScoreIsNaN = errors.New("RESULTING SCORE IS NOT A NUMBER (NAN)")
+var Timeout = errors.New("TIMEOUT")
+var InvalidStreamID = errors.New("INVALID STREAM ID SPECIFIED AS STREAM COMMAND ARGUMENT")
+var StreamIDTooSmall = errors.New("THE ID SPECIFIED IN XADD IS EQUAL OR SMALLER THAN THE TARGET STREAM TOP ITEM")
+var StreamIDOverflow = errors.New("THE STREAM HAS EXHAUSTED THE LAST POSSIBLE ID, UNABLE TO ADD MORE ITEMS")
+var BusyGroup = errors.New("BUSYGROUP CONSUMER GROUP NAME ALREADY EXISTS")
+var NoGroup = errors.New("NOGROUP NO SUCH KEY OR CONSUMER GROUP")
+var XGroupKeyMissing = errors.New("THE XGROUP SUBCOMMAND REQUIRES THE KEY TO EXIST, USE THE MKSTREAM OPTION TO CREATE AN EMPTY STREAM AUTOMATICALLY")
+var IncrOverflow = errors.New("INCREMENT OR DECREMENT WOULD OVERFLOW")
+var IncrNaNOrInfinity = errors.New("INCREMENT WOULD PRODUCE NAN OR INFINITY")
+var OffsetOutOfRange = errors.New("OFFSET IS OUT OF RANGE")
+var StringTooLong = errors.New("STRING EXCEEDS MAXIMUM ALLOWED SIZE (512MB)")
+var NoSuchKey = errors.New("NO SUCH KEY")
+var IndexOutOfRange = errors.New("INDEX OUT OF RANGE")
+var ConnectionClosed = errors.New("CONNECTION CLOSED")
+var Unblocked = errors.New("UNBLOCKED CLIENT UNBLOCKED VIA CLIENT UNBLOCK")
+var HashValueNotAnInteger = errors.New("HASH VALUE IS NOT AN INTEGER")
+var HashValueNotAFloat = errors.New("HASH VALUE IS NOT A FLOAT")
+var SameObject = errors.New("SOURCE AND DESTINATION OBJECTS ARE THE SAME")
+var DBIndexOutOfRange = errors.New("DB INDEX IS OUT OF RANGE")
+var CrossSlot = errors.New("CROSSSLOT KEYS IN REQUEST DON'T HASH TO THE SAME SLOT")
+var ValueOutOfRange = errors.New("VALUE IS OUT OF RANGE")
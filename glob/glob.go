@@ -0,0 +1,106 @@
+package glob
+
+// Match reports whether s matches the redis style glob pattern. The pattern supports
+// * for any run of bytes, ? for a single byte, [abc], [^abc] and [a-z] classes,
+// and \ to match the next byte literally. Matching is done on bytes, not runes.
+func Match(pattern, s string) bool {
+	p, i := 0, 0
+
+	// where to resume when the bytes after the last * stop matching
+	starP, starI := -1, 0
+
+	for i < len(s) {
+		if p < len(pattern) {
+			switch pattern[p] {
+			case '*':
+				starP, starI = p, i
+				p++
+				continue
+
+			case '?':
+				p++
+				i++
+				continue
+
+			case '[':
+				if matched, next := matchClass(pattern, p, s[i]); matched {
+					p = next
+					i++
+					continue
+				}
+
+			case '\\':
+				if p+1 < len(pattern) && pattern[p+1] == s[i] {
+					p += 2
+					i++
+					continue
+				}
+				if p+1 == len(pattern) && s[i] == '\\' {
+					p++
+					i++
+					continue
+				}
+
+			default:
+				if pattern[p] == s[i] {
+					p++
+					i++
+					continue
+				}
+			}
+		}
+
+		if starP < 0 {
+			return false
+		}
+
+		// let the last * swallow one more byte and retry
+		starI++
+		p, i = starP+1, starI
+	}
+
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+
+	return p == len(pattern)
+}
+
+// matchClass matches c against the class opening at pattern[p] and returns the index after it,
+// an unterminated class runs to the end of the pattern
+func matchClass(pattern string, p int, c byte) (bool, int) {
+	p++
+
+	negate := p < len(pattern) && pattern[p] == '^'
+	if negate {
+		p++
+	}
+
+	matched := false
+
+	for p < len(pattern) && pattern[p] != ']' {
+		switch {
+		case pattern[p] == '\\' && p+1 < len(pattern):
+			matched = matched || pattern[p+1] == c
+			p += 2
+
+		case p+2 < len(pattern) && pattern[p+1] == '-' && pattern[p+2] != ']':
+			lo, hi := pattern[p], pattern[p+2]
+			if lo > hi {
+				lo, hi = hi, lo
+			}
+			matched = matched || (lo <= c && c <= hi)
+			p += 3
+
+		default:
+			matched = matched || pattern[p] == c
+			p++
+		}
+	}
+
+	if p < len(pattern) {
+		p++ // the closing ]
+	}
+
+	return matched != negate, p
+}
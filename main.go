@@ -2,11 +2,14 @@ package main
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net"
 	"os"
+	"server/clients"
+	"server/commands"
 	"server/commands/executor"
 	"server/commands/serializer"
 	"server/errs"
@@ -15,6 +18,7 @@ import (
 	"server/store"
 	"server/store/actions"
 	"server/store/cleanup"
+	"server/store/pubsub"
 )
 
 
@@ -27,13 +31,21 @@ func main() {
 
 	defer server.Close()
 
-	store := store.NewStore()
-	executor := executor.NewExecutor(store)
+	hub := pubsub.NewHub()
+	notifier := pubsub.NewNotifier(hub)
+
+	databases := store.NewDatabases(store.DATABASES, notifier)
+	executor := executor.NewExecutor(databases, hub, notifier)
 
 	replayAof(executor)
 
 	aof.StartAof()
-	go cleanup.RunCleanup(store)
+
+	cleanupDatabases := make([]cleanup.Store, len(databases))
+	for i, db := range databases {
+		cleanupDatabases[i] = db
+	}
+	go cleanup.RunCleanup(cleanupDatabases)
 
 	fmt.Println("Accepting connections at port 8080")
 
@@ -49,23 +61,49 @@ func main() {
 }
 
 
+type parsedMessage struct {
+	message any
+	err error
+}
+
 func handleConnection(conn net.Conn, executor *executor.Executor) {
 	defer conn.Close()
 
-	reader := bufio.NewReader(conn)
-	parser := resp.NewParser(reader)
+	client := clients.NewClient()
+	defer client.Close()
+	defer executor.Disconnect(client)
+
 	sr := serializer.NewSerializer()
+	messages := readMessages(conn, client)
+	subscriber := client.Subscriber()
 
 	for {
-		message, err := parser.Parse();
+		var parsed parsedMessage
+
+		// the messages published to the client are written between its commands
+		select {
+		case next, ok := <-messages:
+			if !ok {
+				return
+			}
+			parsed = next
+
+		case frame := <-subscriber.Messages():
+			conn.Write(frame)
+			continue
+
+		case <-subscriber.Overflow():
+			// the client does not keep up with its messages
+			return
+		}
+
+		message, err := parsed.message, parsed.err
 
 		fmt.Println(message)
 
 		if err != nil && err == errs.InvalidDataType{
 			conn.Write(sr.GetErrorBytes(err.Error()))
 			continue
-		} else if err != nil && err == io.EOF{
-			return;
 		} else if err != nil {
 			conn.Write(sr.GetErrorBytes(err.Error()))
 			continue;
@@ -76,13 +114,31 @@ func handleConnection(conn net.Conn, executor *executor.Executor) {
 		fmt.Println(cmd)
 
 		if err != nil {
+			client.AbortTransaction()
 			conn.Write(sr.GetErrorBytes("ERR COULD NOT EXECUTE COMMAND"))
 			continue
 		}
 
-		response := executor.ExecuteCommand(cmd)
-		if _, ok := actions.MutationCommands[cmd.Action]; ok && response[0] != '-' {
+		// a subscribed connection only manages its subscriptions
+		if _, ok := actions.SubscribedModeCommands[cmd.Action]; subscriber.Subscribed() && !ok {
+			conn.Write(sr.GetErrorBytes(fmt.Sprintf("CAN'T EXECUTE '%s': ONLY (P|S)SUBSCRIBE / (P|S)UNSUBSCRIBE / PING / QUIT ARE ALLOWED IN THIS CONTEXT", cmd.Action)))
+			continue
+		}
+
+		response := executor.ExecuteCommand(cmd, client)
+
+		// messages published before the command ran, like an UNSUBSCRIBE, go out before its reply
+		writePendingMessages(conn, subscriber)
+
+		// commands queued by MULTI are logged by the EXEC running them, a blocking command
+		// served by another command is logged along with it
+		if _, ok := actions.MutationCommands[cmd.Action]; ok && response != nil && response[0] != '-' && !client.InTransaction() && !cmd.Logged {
 			aof.AofChan <- cmd
+		} else {
+			// what a served client that left gave back
+			for _, entry := range cmd.Served {
+				aof.AofChan <- entry
+			}
 		}
 
 		if response == nil {
@@ -90,9 +146,55 @@ func handleConnection(conn net.Conn, executor *executor.Executor) {
 			continue
 		}
 		conn.Write(response)
+
+		if cmd.Action == actions.Quit {
+			return
+		}
 	}
 }
 
+func writePendingMessages(conn net.Conn, subscriber *pubsub.Subscriber) {
+	for {
+		select {
+		case frame := <-subscriber.Messages():
+			conn.Write(frame)
+		default:
+			return
+		}
+	}
+}
+
+// readMessages parses the connection in its own goroutine, so that a client leaving is noticed
+// while one of its commands is blocked. The client is closed once the connection is.
+func readMessages(conn net.Conn, client *clients.Client) <-chan parsedMessage {
+	messages := make(chan parsedMessage)
+
+	go func() {
+		defer close(messages)
+		defer client.Close()
+
+		reader := bufio.NewReader(conn)
+		parser := resp.NewParser(reader)
+
+		for {
+			message, err := parser.Parse();
+
+			var netErr net.Error
+			if err == io.EOF || err == io.ErrUnexpectedEOF || errors.As(err, &netErr) {
+				return
+			}
+
+			select {
+			case messages <- parsedMessage{message, err}:
+			case <-client.Context().Done():
+				return
+			}
+		}
+	}()
+
+	return messages
+}
+
 func replayAof(executor *executor.Executor) error {
 	fmt.Println("Started AOF replay")
 
@@ -108,6 +210,9 @@ func replayAof(executor *executor.Executor) error {
 	reader := bufio.NewReader(file)
 	parser := resp.NewParser(reader)
 
+	client := clients.NewClient()
+	defer client.Close()
+
 	for {
 		message, err := parser.Parse()
 		if err == io.EOF {
@@ -122,8 +227,26 @@ func replayAof(executor *executor.Executor) error {
 			return fmt.Errorf("AOF command error: %w", err)
 		}
 
-		executor.ExecuteCommand(cmd)
+		executor.ExecuteCommand(cmd, client)
+	}
+
+	// a transaction cut off by a crash is never run, DISCARD ends it so
+	// the commands appended after it are not queued by the next replay
+	if client.InTransaction() {
+		return discardAofTransaction()
 	}
 
 	return nil
+}
+
+func discardAofTransaction() error {
+	file, err := os.OpenFile("appendonly.aof", os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	sr := serializer.NewSerializer()
+	_, err = file.Write(sr.SerializeCommand(&commands.RedisCommand{Action: actions.Discard}))
+	return err
 }
\ No newline at end of file
@@ -4,6 +4,8 @@ import (
 	"os"
 	"server/commands"
 	"server/commands/serializer"
+	"server/store/actions"
+	"strconv"
 	"sync"
 	"time"
 )
@@ -32,6 +34,8 @@ type Aof struct {
 	byteCommands []byte			// serialized redis commands
 	size int
 
+	db int						// the database selected by the last SELECT written, -1 before the first
+
 	lastFlushed time.Time
 	lastSynced time.Time
 }
@@ -58,6 +62,7 @@ func initAof() *Aof {
 			sr: serializer.NewSerializer(),
 			byteCommands: make([]byte, 0, MAX_BUFFER_BYTES),
 			size: 0,
+			db: -1,
 			lastFlushed: time.Now(),
 			lastSynced: time.Now(),
 		}
@@ -91,7 +96,30 @@ func (aof *Aof) flushOSBuffer() {
 }
 
 func (aof *Aof) AddCommand(cmd *commands.RedisCommand) {
-	serializedCmd := aof.sr.SerializeCommand(cmd)
+	if cmd.Action == actions.Exec {
+		aof.addTransaction(cmd.Transaction)
+		return
+	}
+
+	// the replay runs every command against the last selected database, the file
+	// may already end with any database selected so the first command selects too
+	if cmd.DB != aof.db {
+		aof.db = cmd.DB
+		aof.AddCommand(&commands.RedisCommand{
+			Action:    actions.Select,
+			Arguments: []string{strconv.Itoa(cmd.DB)},
+			DB:        cmd.DB,
+		})
+	}
+
+	aof.appendSerialized(aof.sr.SerializeCommand(cmd))
+
+	for _, served := range cmd.Served {
+		aof.AddCommand(served)
+	}
+}
+
+func (aof *Aof) appendSerialized(serializedCmd []byte) {
 	size := len(serializedCmd)
 
 	// command size itself bigger than the buffer, push immediately
@@ -108,6 +136,22 @@ func (aof *Aof) AddCommand(cmd *commands.RedisCommand) {
 	aof.AppendCmdToBuffer(serializedCmd)
 }
 
+// addTransaction writes the mutations of an EXEC wrapped in MULTI/EXEC, the replay
+// queues them and never runs a transaction whose EXEC did not make it to the file
+func (aof *Aof) addTransaction(cmds []*commands.RedisCommand) {
+	if len(cmds) == 0 {
+		return
+	}
+
+	aof.appendSerialized(aof.sr.SerializeCommand(&commands.RedisCommand{Action: actions.Multi}))
+
+	for _, cmd := range cmds {
+		aof.AddCommand(cmd)
+	}
+
+	aof.appendSerialized(aof.sr.SerializeCommand(&commands.RedisCommand{Action: actions.Exec}))
+}
+
 func (aof *Aof) WriteBytes(buf []byte) error {
 	for len(buf) > 0 {
 		n, err := aof.file.Write(buf)
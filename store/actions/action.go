@@ -11,6 +11,70 @@ const (
 	Exists Action = "exists"
 	Expire Action = "expire"
 	TTL    Action = "ttl"
+	Client Action = "client"
+	Config Action = "config"
+	Quit   Action = "quit"
+
+	Subscribe    Action = "subscribe"
+	Unsubscribe  Action = "unsubscribe"
+	PSubscribe   Action = "psubscribe"
+	PUnsubscribe Action = "punsubscribe"
+	SSubscribe   Action = "ssubscribe"
+	SUnsubscribe Action = "sunsubscribe"
+	Publish      Action = "publish"
+	SPublish     Action = "spublish"
+	PubSub       Action = "pubsub"
+
+	Scan  Action = "scan"
+	HScan Action = "hscan"
+	SScan Action = "sscan"
+	ZScan Action = "zscan"
+
+	Keys      Action = "keys"
+	Type      Action = "type"
+	Rename    Action = "rename"
+	RenameNX  Action = "renamenx"
+	RandomKey Action = "randomkey"
+	DBSize    Action = "dbsize"
+	Touch     Action = "touch"
+	Copy      Action = "copy"
+
+	Select   Action = "select"
+	Move     Action = "move"
+	SwapDB   Action = "swapdb"
+	FlushDB  Action = "flushdb"
+	FlushAll Action = "flushall"
+
+	Multi   Action = "multi"
+	Exec    Action = "exec"
+	Discard Action = "discard"
+	Watch   Action = "watch"
+	Unwatch Action = "unwatch"
+
+	PExpire     Action = "pexpire"
+	ExpireAt    Action = "expireat"
+	PExpireAt   Action = "pexpireat"
+	PTTL        Action = "pttl"
+	Persist     Action = "persist"
+	ExpireTime  Action = "expiretime"
+	PExpireTime Action = "pexpiretime"
+
+	Incr        Action = "incr"
+	Decr        Action = "decr"
+	IncrBy      Action = "incrby"
+	DecrBy      Action = "decrby"
+	IncrByFloat Action = "incrbyfloat"
+	Append      Action = "append"
+	StrLen      Action = "strlen"
+	GetRange    Action = "getrange"
+	SetRange    Action = "setrange"
+
+	MGet   Action = "mget"
+	MSet   Action = "mset"
+	MSetNX Action = "msetnx"
+	GetDel Action = "getdel"
+	GetEx  Action = "getex"
+	GetSet Action = "getset"
 
 	LPush Action = "lpush"
 	LPop  Action = "lpop"
@@ -19,10 +83,91 @@ const (
 	BLPop Action = "blpop"
 	BRPop Action = "brpop"
 
+	LRange  Action = "lrange"
+	LIndex  Action = "lindex"
+	LSet    Action = "lset"
+	LInsert Action = "linsert"
+	LRem    Action = "lrem"
+	LTrim   Action = "ltrim"
+	LLen    Action = "llen"
+	LPos    Action = "lpos"
+
+	LMove      Action = "lmove"
+	BLMove     Action = "blmove"
+	RPopLPush  Action = "rpoplpush"
+	BRPopLPush Action = "brpoplpush"
+
+	LMPop  Action = "lmpop"
+	BLMPop Action = "blmpop"
+
 	HGet    Action = "hget"
 	HSet    Action = "hset"
 	HGetAll Action = "hgetall"
 	HDel    Action = "hdel"
+
+	HLen         Action = "hlen"
+	HKeys        Action = "hkeys"
+	HVals        Action = "hvals"
+	HExists      Action = "hexists"
+	HSetNX       Action = "hsetnx"
+	HMGet        Action = "hmget"
+	HIncrBy      Action = "hincrby"
+	HIncrByFloat Action = "hincrbyfloat"
+	HStrLen      Action = "hstrlen"
+	HRandField   Action = "hrandfield"
+
+	HExpire    Action = "hexpire"
+	HPExpire   Action = "hpexpire"
+	HExpireAt  Action = "hexpireat"
+	HPExpireAt Action = "hpexpireat"
+	HTTL       Action = "httl"
+	HPTTL      Action = "hpttl"
+	HPersist   Action = "hpersist"
+
+	SAdd      Action = "sadd"
+	SRem      Action = "srem"
+	SMembers  Action = "smembers"
+	SIsMember Action = "sismember"
+	SCard     Action = "scard"
+
+	SInter      Action = "sinter"
+	SUnion      Action = "sunion"
+	SDiff       Action = "sdiff"
+	SInterStore Action = "sinterstore"
+	SUnionStore Action = "sunionstore"
+	SDiffStore  Action = "sdiffstore"
+
+	ZAdd     Action = "zadd"
+	ZRange   Action = "zrange"
+	ZRank    Action = "zrank"
+	ZRevRank Action = "zrevrank"
+	ZScore   Action = "zscore"
+	ZIncrBy  Action = "zincrby"
+	ZRem     Action = "zrem"
+	ZCard    Action = "zcard"
+
+	ZPopMin          Action = "zpopmin"
+	ZPopMax          Action = "zpopmax"
+	BZPopMin         Action = "bzpopmin"
+	BZPopMax         Action = "bzpopmax"
+	ZRemRangeByScore Action = "zremrangebyscore"
+	ZRemRangeByRank  Action = "zremrangebyrank"
+	ZRemRangeByLex   Action = "zremrangebylex"
+
+	XAdd      Action = "xadd"
+	XRange    Action = "xrange"
+	XRevRange Action = "xrevrange"
+	XLen      Action = "xlen"
+	XDel      Action = "xdel"
+	XTrim     Action = "xtrim"
+	XRead     Action = "xread"
+
+	XGroup     Action = "xgroup"
+	XReadGroup Action = "xreadgroup"
+	XAck       Action = "xack"
+	XPending   Action = "xpending"
+	XClaim     Action = "xclaim"
+	XAutoClaim Action = "xautoclaim"
 )
 
 var ValidCommands = map[Action]struct{}{
@@ -34,6 +179,70 @@ var ValidCommands = map[Action]struct{}{
 	Expire: {},
 	TTL:    {},
 	Echo:   {},
+	Client: {},
+	Config: {},
+	Quit:   {},
+
+	Subscribe:    {},
+	Unsubscribe:  {},
+	PSubscribe:   {},
+	PUnsubscribe: {},
+	SSubscribe:   {},
+	SUnsubscribe: {},
+	Publish:      {},
+	SPublish:     {},
+	PubSub:       {},
+
+	Scan:  {},
+	HScan: {},
+	SScan: {},
+	ZScan: {},
+
+	Keys:      {},
+	Type:      {},
+	Rename:    {},
+	RenameNX:  {},
+	RandomKey: {},
+	DBSize:    {},
+	Touch:     {},
+	Copy:      {},
+
+	Select:   {},
+	Move:     {},
+	SwapDB:   {},
+	FlushDB:  {},
+	FlushAll: {},
+
+	Multi:   {},
+	Exec:    {},
+	Discard: {},
+	Watch:   {},
+	Unwatch: {},
+
+	PExpire:     {},
+	ExpireAt:    {},
+	PExpireAt:   {},
+	PTTL:        {},
+	Persist:     {},
+	ExpireTime:  {},
+	PExpireTime: {},
+
+	Incr:        {},
+	Decr:        {},
+	IncrBy:      {},
+	DecrBy:      {},
+	IncrByFloat: {},
+	Append:      {},
+	StrLen:      {},
+	GetRange:    {},
+	SetRange:    {},
+
+	MGet:   {},
+	MSet:   {},
+	MSetNX: {},
+	GetDel: {},
+	GetEx:  {},
+	GetSet: {},
 
 	LPop:  {},
 	LPush: {},
@@ -42,10 +251,103 @@ var ValidCommands = map[Action]struct{}{
 	BLPop: {},
 	BRPop: {},
 
+	LRange:  {},
+	LIndex:  {},
+	LSet:    {},
+	LInsert: {},
+	LRem:    {},
+	LTrim:   {},
+	LLen:    {},
+	LPos:    {},
+
+	LMove:      {},
+	BLMove:     {},
+	RPopLPush:  {},
+	BRPopLPush: {},
+
+	LMPop:  {},
+	BLMPop: {},
+
 	HSet:    {},
 	HGet:    {},
 	HGetAll: {},
 	HDel:    {},
+
+	HLen:         {},
+	HKeys:        {},
+	HVals:        {},
+	HExists:      {},
+	HSetNX:       {},
+	HMGet:        {},
+	HIncrBy:      {},
+	HIncrByFloat: {},
+	HStrLen:      {},
+	HRandField:   {},
+
+	HExpire:    {},
+	HPExpire:   {},
+	HExpireAt:  {},
+	HPExpireAt: {},
+	HTTL:       {},
+	HPTTL:      {},
+	HPersist:   {},
+
+	SAdd:      {},
+	SRem:      {},
+	SMembers:  {},
+	SIsMember: {},
+	SCard:     {},
+
+	SInter:      {},
+	SUnion:      {},
+	SDiff:       {},
+	SInterStore: {},
+	SUnionStore: {},
+	SDiffStore:  {},
+
+	ZAdd:     {},
+	ZRange:   {},
+	ZRank:    {},
+	ZRevRank: {},
+	ZScore:   {},
+	ZIncrBy:  {},
+	ZRem:     {},
+	ZCard:    {},
+
+	ZPopMin:          {},
+	ZPopMax:          {},
+	BZPopMin:         {},
+	BZPopMax:         {},
+	ZRemRangeByScore: {},
+	ZRemRangeByRank:  {},
+	ZRemRangeByLex:   {},
+
+	XAdd:      {},
+	XRange:    {},
+	XRevRange: {},
+	XLen:      {},
+	XDel:      {},
+	XTrim:     {},
+	XRead:     {},
+
+	XGroup:     {},
+	XReadGroup: {},
+	XAck:       {},
+	XPending:   {},
+	XClaim:     {},
+	XAutoClaim: {},
+}
+
+// SubscribedModeCommands are the only commands a connection with subscriptions can run
+var SubscribedModeCommands = map[Action]struct{}{
+	Subscribe:    {},
+	Unsubscribe:  {},
+	PSubscribe:   {},
+	PUnsubscribe: {},
+	SSubscribe:   {},
+	SUnsubscribe: {},
+	Ping:         {},
+	Quit:         {},
 }
 
 type BlockingPopDirection Action
@@ -53,6 +355,9 @@ type BlockingPopDirection Action
 const (
 	BLEFT  BlockingPopDirection = "left"
 	BRIGHT BlockingPopDirection = "right" // Blocking Right
+
+	BMIN BlockingPopDirection = "min" // lowest score of a sorted set
+	BMAX BlockingPopDirection = "max" // highest score of a sorted set
 )
 
 var MutationCommands = map[Action]struct{}{
@@ -60,13 +365,94 @@ var MutationCommands = map[Action]struct{}{
 	Del:    {},
 	Expire: {},
 
+	Rename:   {},
+	RenameNX: {},
+	Copy:     {},
+
+	// SELECT is written by the AOF itself whenever the database changes
+	Move:     {},
+	SwapDB:   {},
+	FlushDB:  {},
+	FlushAll: {},
+
+	// EXEC is logged as the mutations it ran, wrapped in MULTI/EXEC
+	Exec: {},
+
+	// EXPIRE, PEXPIRE and EXPIREAT are logged as the PEXPIREAT they resolved to
+	PExpire:   {},
+	ExpireAt:  {},
+	PExpireAt: {},
+	Persist:   {},
+
+	Incr:        {},
+	Decr:        {},
+	IncrBy:      {},
+	DecrBy:      {},
+	IncrByFloat: {},
+	Append:      {},
+	SetRange:    {},
+
+	// GETEX is logged with an absolute PXAT expiry
+	MSet:   {},
+	MSetNX: {},
+	GetDel: {},
+	GetEx:  {},
+	GetSet: {},
+
+	// BLPOP, BRPOP and BLMPOP are logged as the LPOP or RPOP they resolved to
 	LPop:  {},
 	LPush: {},
 	RPush: {},
 	RPop:  {},
-	BLPop: {},
-	BRPop: {},
+
+	LSet:    {},
+	LInsert: {},
+	LRem:    {},
+	LTrim:   {},
+
+	// BLMOVE and BRPOPLPUSH are logged as the LMOVE they resolved to
+	LMove:     {},
+	RPopLPush: {},
+	LMPop:     {},
 
 	HSet:    {},
 	HDel:    {},
+
+	HSetNX:       {},
+	HIncrBy:      {},
+	HIncrByFloat: {},
+
+	// HEXPIRE, HPEXPIRE and HEXPIREAT are logged as the HPEXPIREAT they resolved to
+	HPExpireAt: {},
+	HPersist:   {},
+
+	SAdd: {},
+	SRem: {},
+
+	SInterStore: {},
+	SUnionStore: {},
+	SDiffStore:  {},
+
+	ZAdd:    {},
+	ZIncrBy: {},
+	ZRem:    {},
+
+	// BZPOPMIN/BZPOPMAX are logged as the ZPOPMIN/ZPOPMAX they resolved to
+	ZPopMin:          {},
+	ZPopMax:          {},
+	ZRemRangeByScore: {},
+	ZRemRangeByRank:  {},
+	ZRemRangeByLex:   {},
+
+	// XADD is logged with the ID it generated
+	XAdd:  {},
+	XDel:  {},
+	XTrim: {},
+
+	// XREADGROUP is logged without BLOCK, XCLAIM and XAUTOCLAIM as the claims they made
+	XGroup:     {},
+	XReadGroup: {},
+	XAck:       {},
+	XClaim:     {},
+	XAutoClaim: {},
 }
\ No newline at end of file
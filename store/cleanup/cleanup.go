@@ -4,21 +4,27 @@ import (
 	"time"
 )
 
+// Store deletes a key or hash field once its queued expiry passed, publishing the
+// expired keyspace notifications. An expiry that is no longer current is skipped.
 type Store interface {
-	Delete(keys []string) int
-	GetExpiry(key string) (time.Time, error) 
+	ExpireKey(key string, at time.Time)
+	ExpireHashField(key, field string, at time.Time)
 }
 
-var wakeUpChannel chan struct{} = make(chan struct{})
+// buffered so that pending wake ups coalesce into one
+var wakeUpChannel chan struct{} = make(chan struct{}, 1)
 
-func RunCleanup(store Store) {
+// RunCleanup deletes expired keys and hash fields, databases is indexed by database number
+func RunCleanup(databases []Store) {
 	pq := GetPQ()
 
 	for {
 		length := pq.Len()
 
+		// a wake up may be left over from a push that has been handled already
 		if length == 0 {
 			<-wakeUpChannel
+			continue
 		}
 
 		nextExpiry := pq.PeekNext()
@@ -35,15 +41,20 @@ func RunCleanup(store Store) {
 			}
 		}
 
+		// the top may have changed since the peek, an expiry that is not due yet goes back
 		nextExpiry = pq.PopNext()
+		if time.Now().Before(nextExpiry.at) {
+			pq.push(nextExpiry)
+			continue
+		}
 
-		storeElementExpiryAt, err := store.GetExpiry(nextExpiry.key)
+		store := databases[nextExpiry.db]
 
-		if err != nil || storeElementExpiryAt != nextExpiry.at {
+		if nextExpiry.isField {
+			store.ExpireHashField(nextExpiry.key, nextExpiry.field, nextExpiry.at)
 			continue
 		}
 
-		// The GetExpiry call itself does the deletion but keeping it just in case
-		store.Delete([]string{nextExpiry.key})
+		store.ExpireKey(nextExpiry.key, nextExpiry.at)
 	}
-}
\ No newline at end of file
+}
@@ -7,8 +7,12 @@ import (
 )
 
 type Expiry struct {
+	db  int
 	key string
 	at  time.Time
+
+	field   string // the hash field that expires, when isField is set
+	isField bool
 }
 
 type ExpiryPQ struct {
@@ -51,11 +55,26 @@ func (pq *ExpiryPQ) PopNext() *Expiry {
 	return heap.Pop(pq).(*Expiry)
 }
 
-func (pq *ExpiryPQ) HPush(key string, expireAt time.Time) {
-	item := &Expiry{
+func (pq *ExpiryPQ) HPush(db int, key string, expireAt time.Time) {
+	pq.push(&Expiry{
+		db: db,
 		key: key,
 		at: expireAt,
-	}
+	})
+}
+
+// HPushField queues the expiry of a single hash field
+func (pq *ExpiryPQ) HPushField(db int, key, field string, expireAt time.Time) {
+	pq.push(&Expiry{
+		db: db,
+		key: key,
+		at: expireAt,
+		field: field,
+		isField: true,
+	})
+}
+
+func (pq *ExpiryPQ) push(item *Expiry) {
 	pq.mu.Lock()
 	defer pq.mu.Unlock()
 
@@ -67,8 +86,13 @@ func (pq *ExpiryPQ) HPush(key string, expireAt time.Time) {
 	heap.Push(pq, item)
 
 	// newly pushed item has an expiry which precedes the current top
+	// the wake up never blocks: expiries are pushed under the store lock, and during
+	// the AOF replay the cleanup goroutine is not running yet
 	if oldTop == nil || item.at.Before(oldTop.at) {
-		wakeUpChannel <- struct{}{}
+		select {
+		case wakeUpChannel <- struct{}{}:
+		default:
+		}
 	}
 }
 
@@ -0,0 +1,180 @@
+package store
+
+import (
+	"server/store/cleanup"
+	"server/store/objects"
+	"server/store/pubsub"
+	"sync"
+)
+
+// ———————————————————————————————————————————————————————————————
+// Numbered databases
+// ———————————————————————————————————————————————————————————————
+
+const DATABASES = 16
+
+// NewDatabases creates n empty databases. They share one lock, so that commands
+// spanning databases like MOVE and SWAPDB are atomic. Their keyspace notifications
+// go through notifier.
+func NewDatabases(n int, notifier *pubsub.Notifier) []*Store {
+	mu := &sync.RWMutex{}
+
+	databases := make([]*Store, n)
+	for i := range databases {
+		databases[i] = newStore(i, mu, notifier)
+	}
+
+	return databases
+}
+
+func (store *Store) Index() int {
+	return store.index
+}
+
+// queueExpiries queues the key and hash field expiries of the object in the cleanup,
+// they are queued by database and key name
+func (store *Store) queueExpiries(key string, object *objects.Object) {
+	// must be used with a lock
+	if expiry := object.GetExpiry(); expiry != nil {
+		cleanup.GetPQ().HPush(store.index, key, expiry.At)
+	}
+
+	if hs, ok := object.Data.(*objects.HashSet); ok {
+		for field, at := range hs.FieldExpiries() {
+			cleanup.GetPQ().HPushField(store.index, key, field, at)
+		}
+	}
+}
+
+// serveWaiters serves the clients blocked on key from the object now stored there
+func (store *Store) serveWaiters(key string, object *objects.Object) ([]objects.BlockingPopDisperal, []objects.ZSetBlockingPopDispersal) {
+	// must be used with a lock
+	switch data := object.Data.(type) {
+	case *objects.RedisList:
+		return store.serveListClients(key, data), nil
+
+	case *objects.SortedSet:
+		return nil, store.serveZSetClients(key, data)
+
+	case *objects.RedisStream:
+		store.signalStreamWaiters(key)
+	}
+
+	return nil, nil
+}
+
+// waitedKeys returns the keys clients are blocked on in this database
+func (store *Store) waitedKeys() map[string]struct{} {
+	// must be used with a lock
+	keys := make(map[string]struct{})
+
+	for key := range store.listWaiters {
+		keys[key] = struct{}{}
+	}
+
+	for key := range store.zsetWaiters {
+		keys[key] = struct{}{}
+	}
+
+	for key := range store.streamWaiters {
+		keys[key] = struct{}{}
+	}
+
+	return keys
+}
+
+// Move moves the key, with its TTL, to the target database unless it exists there
+func (store *Store) Move(key string, target *Store) bool {
+	store.mu.Lock()
+
+	object, exists := store.getObject(key)
+	if !exists {
+		store.mu.Unlock()
+		return false
+	}
+
+	if _, exists := target.getObject(key); exists {
+		store.mu.Unlock()
+		return false
+	}
+
+	store.kvMap.Delete(key)
+	store.keyModified(key)
+	listDispersals, zsetDispersals := target.putObject(key, object)
+	store.notify(pubsub.NotifyGeneric, "move_from", key)
+	target.notify(pubsub.NotifyGeneric, "move_to", key)
+	store.mu.Unlock()
+
+	store.disperseToListClients(listDispersals)
+	store.disperseToZSetClients(zsetDispersals)
+
+	return true
+}
+
+// FlushDB deletes every key of the database
+func (store *Store) FlushDB() {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.flush()
+}
+
+func (store *Store) flush() {
+	// must be used with a lock
+	// the blocked clients keep waiting, expiries left in the cleanup no longer
+	// match anything and are skipped
+	store.allKeysModified()
+	store.kvMap = objects.NewDict[*objects.Object]()
+}
+
+// FlushAll deletes every key of every database
+func FlushAll(databases []*Store) {
+	databases[0].mu.Lock()
+	defer databases[0].mu.Unlock()
+
+	for _, store := range databases {
+		store.flush()
+	}
+}
+
+// SwapDatabases exchanges the keys of two databases. Clients connected to either database
+// see the other one's keys, and the clients blocked in a database stay blocked in it,
+// served right away when the swapped in keys have what they wait for. Their pops are
+// logged with the command a is a view of.
+func SwapDatabases(a, b *Store) {
+	if a == b {
+		return
+	}
+
+	a.mu.Lock()
+
+	// a watched key changes when it exists on either side
+	a.allKeysModified()
+	b.allKeysModified()
+	a.kvMap, b.kvMap = b.kvMap, a.kvMap
+	a.allKeysModified()
+	b.allKeysModified()
+
+	listDispersals, zsetDispersals := []objects.BlockingPopDisperal{}, []objects.ZSetBlockingPopDispersal{}
+
+	for _, store := range []*Store{a, b} {
+		// expiries are queued by database so the swapped keys are queued again
+		for key, object := range store.kvMap.All() {
+			store.queueExpiries(key, object)
+		}
+
+		// the clients blocked in the database are served from the swapped in keys
+		for key := range store.waitedKeys() {
+			if object, exists := store.getObject(key); exists {
+				served, zsetServed := store.serveWaiters(key, object)
+				listDispersals = append(listDispersals, served...)
+				zsetDispersals = append(zsetDispersals, zsetServed...)
+			}
+		}
+	}
+
+	a.mu.Unlock()
+
+	a.disperseToListClients(listDispersals)
+	a.disperseToZSetClients(zsetDispersals)
+}
@@ -0,0 +1,417 @@
+package store
+
+import (
+	"math"
+	"server/errs"
+	"server/store/actions"
+	"server/store/cleanup"
+	"server/store/objects"
+	"server/store/pubsub"
+	"strconv"
+	"time"
+)
+
+// ———————————————————————————————————————————————————————————————
+// Hash methods
+// ———————————————————————————————————————————————————————————————
+
+// getHash returns the hash stored at key, the expired fields are hidden by the hash itself
+// and a hash whose fields all expired is deleted
+func (store *Store) getHash(key string, action actions.Action) (*objects.HashSet, error) {
+	// must be used with a lock
+	object, exists := store.getObject(key)
+	if !exists {
+		return nil, errs.ErrNotFound
+	}
+
+	object, err := store.validateActionForDataType(object, action)
+	if err != nil {
+		return nil, err
+	}
+
+	hs, err := objects.ValidateObjectAsHash(object)
+	if err != nil {
+		return nil, err
+	}
+
+	if hs.Len() == 0 {
+		store.kvMap.Delete(key)
+		store.keyModified(key)
+		store.notifyRemoval(pubsub.NotifyHash, "hexpired", key, true)
+		return nil, errs.ErrNotFound
+	}
+
+	return hs, nil
+}
+
+func (store *Store) getOrCreateHash(key string, action actions.Action) (*objects.HashSet, error) {
+	// must be used with a lock
+	hs, err := store.getHash(key, action)
+	if err == errs.ErrNotFound {
+		hs = objects.NewHashSet()
+		store.kvMap.Set(key, objects.NewObject(objects.Hash, hs))
+		return hs, nil
+	}
+
+	return hs, err
+}
+
+func (store *Store) deleteHashIfEmpty(key string, hs *objects.HashSet) {
+	// must be used with a lock
+	if hs.Len() == 0 {
+		store.kvMap.Delete(key)
+	}
+}
+
+func (store *Store) HLen(key string) (int, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	hs, err := store.getHash(key, actions.HLen)
+	if err != nil {
+		return 0, err
+	}
+
+	return hs.Len(), nil
+}
+
+func (store *Store) HKeys(key string) ([]string, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	hs, err := store.getHash(key, actions.HKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	return hs.Fields(), nil
+}
+
+func (store *Store) HVals(key string) ([]string, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	hs, err := store.getHash(key, actions.HVals)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]string, 0, hs.Len())
+	for _, value := range hs.All() {
+		values = append(values, value)
+	}
+
+	return values, nil
+}
+
+func (store *Store) HExists(key, field string) (bool, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	hs, err := store.getHash(key, actions.HExists)
+	if err != nil {
+		return false, err
+	}
+
+	return hs.Exists(field), nil
+}
+
+// HSetNX sets the field only when it does not exist yet, reporting whether it did
+func (store *Store) HSetNX(key, field, value string) (bool, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	hs, err := store.getOrCreateHash(key, actions.HSetNX)
+	if err != nil {
+		return false, err
+	}
+
+	if hs.Exists(field) {
+		return false, nil
+	}
+
+	hs.Set(field, value)
+	store.keyModified(key)
+	store.notify(pubsub.NotifyHash, "hset", key)
+
+	return true, nil
+}
+
+// HMGet returns the value of every field, nil for missing ones
+func (store *Store) HMGet(key string, fields []string) ([]*string, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	values := make([]*string, len(fields))
+
+	hs, err := store.getHash(key, actions.HMGet)
+	if err == errs.ErrNotFound {
+		return values, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i, field := range fields {
+		if value, exists := hs.Get(field); exists {
+			values[i] = &value
+		}
+	}
+
+	return values, nil
+}
+
+func (store *Store) HIncrBy(key, field string, delta int64) (int64, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	hs, err := store.getOrCreateHash(key, actions.HIncrBy)
+	if err != nil {
+		return 0, err
+	}
+
+	current := int64(0)
+	if value, exists := hs.Get(field); exists {
+		current, err = strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return 0, errs.HashValueNotAnInteger
+		}
+	}
+
+	if (delta > 0 && current > math.MaxInt64-delta) || (delta < 0 && current < math.MinInt64-delta) {
+		return 0, errs.IncrOverflow
+	}
+
+	current += delta
+	hs.Update(field, strconv.FormatInt(current, 10))
+	store.keyModified(key)
+	store.notify(pubsub.NotifyHash, "hincrby", key)
+
+	return current, nil
+}
+
+func (store *Store) HIncrByFloat(key, field string, delta float64) (float64, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	hs, err := store.getOrCreateHash(key, actions.HIncrByFloat)
+	if err != nil {
+		return 0, err
+	}
+
+	current := float64(0)
+	if value, exists := hs.Get(field); exists {
+		current, err = strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(current) || math.IsInf(current, 0) {
+			return 0, errs.HashValueNotAFloat
+		}
+	}
+
+	current += delta
+	if math.IsNaN(current) || math.IsInf(current, 0) {
+		return 0, errs.IncrNaNOrInfinity
+	}
+
+	hs.Update(field, FormatFloat(current))
+	store.keyModified(key)
+	store.notify(pubsub.NotifyHash, "hincrbyfloat", key)
+
+	return current, nil
+}
+
+func (store *Store) HStrLen(key, field string) (int, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	hs, err := store.getHash(key, actions.HStrLen)
+	if err != nil {
+		return 0, err
+	}
+
+	value, _ := hs.Get(field)
+
+	return len(value), nil
+}
+
+// HRandField returns random fields and their values, see HashSet.RandomFields for count
+func (store *Store) HRandField(key string, count int) ([]string, []string, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	hs, err := store.getHash(key, actions.HRandField)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if hs.Len() == 0 {
+		return []string{}, []string{}, nil
+	}
+
+	fields := hs.RandomFields(count)
+	values := make([]string, len(fields))
+	for i, field := range fields {
+		values[i], _ = hs.Get(field)
+	}
+
+	return fields, values, nil
+}
+
+// ———————————————————————————————————————————————————————————————
+// Hash field expiry
+// ———————————————————————————————————————————————————————————————
+
+const (
+	FieldNotFound    = -2 // the field does not exist
+	FieldHasNoExpiry = -1 // the field exists but does not expire
+	FieldNotExpired  = 0  // the expire condition did not hold
+	FieldExpirySet   = 1  // the expiry was set or removed
+	FieldDeleted     = 2  // the expiry was in the past and the field got deleted
+)
+
+func (store *Store) setFieldExpiry(key string, hs *objects.HashSet, field string, at time.Time) {
+	// must be used with a lock
+	hs.ExpireFieldAt(field, at)
+	cleanup.GetPQ().HPushField(store.index, key, field, at)
+}
+
+// HExpireAt sets the expiry of each field for which the condition holds and returns
+// one of the Field* codes per field, an expiry in the past deletes the field
+func (store *Store) HExpireAt(key string, at time.Time, condition ExpireCondition, fields []string) ([]int, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	hs, err := store.getHash(key, actions.HPExpireAt)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]int, len(fields))
+	expired := !time.Now().Before(at)
+	changed := false
+
+	for i, field := range fields {
+		if !hs.Exists(field) {
+			results[i] = FieldNotFound
+			continue
+		}
+
+		current, hasExpiry := hs.FieldExpiry(field)
+		if !condition.allows(current, hasExpiry, at) {
+			results[i] = FieldNotExpired
+			continue
+		}
+
+		store.keyModified(key)
+		changed = true
+
+		if expired {
+			hs.Delete([]string{field})
+			results[i] = FieldDeleted
+			continue
+		}
+
+		store.setFieldExpiry(key, hs, field, at)
+		results[i] = FieldExpirySet
+	}
+
+	// an expiry in the past deletes the fields like HDEL does
+	switch {
+	case changed && expired:
+		store.notifyRemoval(pubsub.NotifyHash, "hdel", key, hs.Len() == 0)
+	case changed:
+		store.notify(pubsub.NotifyHash, "hexpire", key)
+	}
+
+	store.deleteHashIfEmpty(key, hs)
+
+	return results, nil
+}
+
+// HPTTL returns the remaining time to live of each field in milliseconds,
+// or FieldNotFound and FieldHasNoExpiry
+func (store *Store) HPTTL(key string, fields []string) ([]int64, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	hs, err := store.getHash(key, actions.HPTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]int64, len(fields))
+
+	for i, field := range fields {
+		at, hasExpiry := hs.FieldExpiry(field)
+
+		switch {
+		case !hs.Exists(field):
+			results[i] = FieldNotFound
+		case !hasExpiry:
+			results[i] = FieldHasNoExpiry
+		default:
+			results[i] = max(at.UnixMilli()-time.Now().UnixMilli(), 0)
+		}
+	}
+
+	return results, nil
+}
+
+// HPersist removes the expiry of each field, returning FieldExpirySet,
+// FieldNotFound or FieldHasNoExpiry per field
+func (store *Store) HPersist(key string, fields []string) ([]int, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	hs, err := store.getHash(key, actions.HPersist)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]int, len(fields))
+	persisted := false
+
+	for i, field := range fields {
+		switch {
+		case !hs.Exists(field):
+			results[i] = FieldNotFound
+		// the entry left in the cleanup queue no longer matches and is skipped
+		case hs.PersistField(field):
+			results[i] = FieldExpirySet
+			store.keyModified(key)
+			persisted = true
+		default:
+			results[i] = FieldHasNoExpiry
+		}
+	}
+
+	if persisted {
+		store.notify(pubsub.NotifyHash, "hpersist", key)
+	}
+
+	return results, nil
+}
+
+// ExpireHashField is used by the cleanup to delete a field once its queued expiry passed,
+// nothing happens when the field was rewritten, persisted or already expired lazily, or
+// when the expiry is still to come
+func (store *Store) ExpireHashField(key, field string, at time.Time) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	// the lookup deletes the hash once all of its fields expired
+	hs, err := store.getHash(key, actions.HPTTL)
+	if err != nil {
+		return
+	}
+
+	current, hasExpiry := hs.FieldExpiry(field)
+	if !hasExpiry || !current.Equal(at) || time.Now().Before(at) {
+		return
+	}
+
+	hs.Delete([]string{field})
+	store.keyModified(key)
+	store.notifyRemoval(pubsub.NotifyHash, "hexpired", key, hs.Len() == 0)
+	store.deleteHashIfEmpty(key, hs)
+}
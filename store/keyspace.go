@@ -0,0 +1,153 @@
+package store
+
+import (
+	"server/errs"
+	"server/store/objects"
+	"server/store/pubsub"
+)
+
+// ———————————————————————————————————————————————————————————————
+// Keyspace methods
+// ———————————————————————————————————————————————————————————————
+
+// putObject stores the object under key, queueing its expiries again. The clients
+// blocked on the key are served from the object.
+func (store *Store) putObject(key string, object *objects.Object) ([]objects.BlockingPopDisperal, []objects.ZSetBlockingPopDispersal) {
+	// must be used with a lock
+	store.kvMap.Set(key, object)
+	store.keyModified(key)
+
+	store.queueExpiries(key, object)
+
+	return store.serveWaiters(key, object)
+}
+
+// Keys returns every key matching the glob pattern
+func (store *Store) Keys(pattern string) []string {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	keys := []string{}
+	for key := range store.kvMap.Names() {
+		if _, exists := store.getObject(key); exists && matches(pattern, key) {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+// Type returns the data type of the key, "none" when it does not exist
+func (store *Store) Type(key string) string {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	object, exists := store.getObject(key)
+	if !exists {
+		return "none"
+	}
+
+	return string(object.DataType)
+}
+
+// Rename moves the object, with its TTL, from source to destination. With nx set nothing
+// happens when destination exists, the returned bool reports whether the key was renamed.
+func (store *Store) Rename(source, destination string, nx bool) (bool, error) {
+	store.mu.Lock()
+
+	object, exists := store.getObject(source)
+	if !exists {
+		store.mu.Unlock()
+		return false, errs.NoSuchKey
+	}
+
+	if source == destination {
+		store.mu.Unlock()
+		return !nx, nil
+	}
+
+	if _, exists := store.getObject(destination); exists && nx {
+		store.mu.Unlock()
+		return false, nil
+	}
+
+	store.kvMap.Delete(source)
+	store.keyModified(source)
+	listDispersals, zsetDispersals := store.putObject(destination, object)
+	store.notify(pubsub.NotifyGeneric, "rename_from", source)
+	store.notify(pubsub.NotifyGeneric, "rename_to", destination)
+	store.mu.Unlock()
+
+	store.disperseToListClients(listDispersals)
+	store.disperseToZSetClients(zsetDispersals)
+
+	return true, nil
+}
+
+// Copy stores a deep copy of source, with its TTL, at destination in the target database.
+// Unless replace is set nothing happens when destination exists, the returned bool reports
+// whether it was copied.
+func (store *Store) Copy(source, destination string, target *Store, replace bool) (bool, error) {
+	store.mu.Lock()
+
+	if source == destination && store == target {
+		store.mu.Unlock()
+		return false, errs.SameObject
+	}
+
+	object, exists := store.getObject(source)
+	if !exists {
+		store.mu.Unlock()
+		return false, nil
+	}
+
+	if _, exists := target.getObject(destination); exists && !replace {
+		store.mu.Unlock()
+		return false, nil
+	}
+
+	listDispersals, zsetDispersals := target.putObject(destination, object.Copy())
+	target.notify(pubsub.NotifyGeneric, "copy_to", destination)
+	store.mu.Unlock()
+
+	store.disperseToListClients(listDispersals)
+	store.disperseToZSetClients(zsetDispersals)
+
+	return true, nil
+}
+
+// RandomKey returns a random key, relying on the randomized map iteration order
+func (store *Store) RandomKey() (string, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	for key := range store.kvMap.Names() {
+		if _, exists := store.getObject(key); exists {
+			return key, nil
+		}
+	}
+
+	return "", errs.ErrNotFound
+}
+
+func (store *Store) DBSize() int {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	count := 0
+	for _, object := range store.kvMap.All() {
+		if !object.HasExpired() {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Touch returns how many of the keys exist, there is no access time to update
+func (store *Store) Touch(keys []string) int {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return store.countExisting(keys)
+}
@@ -0,0 +1,571 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"server/errs"
+	"server/store/actions"
+	"server/store/objects"
+	"server/store/pubsub"
+	"strconv"
+	"time"
+)
+
+// ———————————————————————————————————————————————————————————————
+// List methods
+// ———————————————————————————————————————————————————————————————
+
+func (store *Store) getList(key string, action actions.Action) (*objects.RedisList, error) {
+	// must be used with a lock
+	object, exists := store.getObject(key)
+	if !exists {
+		return nil, errs.ErrNotFound
+	}
+
+	object, err := store.validateActionForDataType(object, action)
+	if err != nil {
+		return nil, err
+	}
+
+	return objects.ValidateObjectAsList(object)
+}
+
+func (store *Store) deleteListIfEmpty(key string, list *objects.RedisList) {
+	// must be used with a lock
+	// a move may already have replaced the key with a new list
+	object, exists := store.kvMap.Get(key)
+	if exists && object.Data == any(list) && list.IsEmpty() {
+		store.kvMap.Delete(key)
+	}
+}
+
+func (store *Store) LLen(key string) (int, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	list, err := store.getList(key, actions.LLen)
+	if err != nil {
+		return 0, err
+	}
+
+	return list.GetSize(), nil
+}
+
+func (store *Store) LRange(key string, start, stop int) ([]string, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	list, err := store.getList(key, actions.LRange)
+	if err != nil {
+		return nil, err
+	}
+
+	return list.Range(start, stop), nil
+}
+
+func (store *Store) LIndex(key string, index int) (string, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	list, err := store.getList(key, actions.LIndex)
+	if err != nil {
+		return "", err
+	}
+
+	value, ok := list.Index(index)
+	if !ok {
+		return "", errs.ErrNotFound
+	}
+
+	return value, nil
+}
+
+func (store *Store) LSet(key string, index int, value string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	list, err := store.getList(key, actions.LSet)
+	if err == errs.ErrNotFound {
+		return errs.NoSuchKey
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if !list.Set(index, value) {
+		return errs.IndexOutOfRange
+	}
+	store.keyModified(key)
+	store.notify(pubsub.NotifyList, "lset", key)
+
+	return nil
+}
+
+// LInsert returns the new length of the list, or -1 when pivot is not found
+func (store *Store) LInsert(key string, before bool, pivot, value string) (int, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	list, err := store.getList(key, actions.LInsert)
+	if err != nil {
+		return 0, err
+	}
+
+	length := list.Insert(pivot, value, before)
+	if length > 0 {
+		store.keyModified(key)
+		store.notify(pubsub.NotifyList, "linsert", key)
+	}
+
+	return length, nil
+}
+
+func (store *Store) LRem(key string, count int, value string) (int, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	list, err := store.getList(key, actions.LRem)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := list.Remove(count, value)
+	if removed > 0 {
+		store.keyModified(key)
+		store.notifyRemoval(pubsub.NotifyList, "lrem", key, list.IsEmpty())
+	}
+	store.deleteListIfEmpty(key, list)
+
+	return removed, nil
+}
+
+func (store *Store) LTrim(key string, start, stop int) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	list, err := store.getList(key, actions.LTrim)
+	if err != nil {
+		return err
+	}
+
+	list.Trim(start, stop)
+	store.keyModified(key)
+	store.notifyRemoval(pubsub.NotifyList, "ltrim", key, list.IsEmpty())
+	store.deleteListIfEmpty(key, list)
+
+	return nil
+}
+
+func (store *Store) LPos(key string, value string, rank, count, maxLen int) ([]int, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	list, err := store.getList(key, actions.LPos)
+	if err != nil {
+		return nil, err
+	}
+
+	return list.Pos(value, rank, count, maxLen), nil
+}
+
+func (store *Store) getOrCreateList(key string, action actions.Action) (*objects.RedisList, error) {
+	// must be used with a lock
+	list, err := store.getList(key, action)
+	if err == errs.ErrNotFound {
+		list = objects.NewList()
+		store.kvMap.Set(key, objects.NewObject(objects.List, list))
+		return list, nil
+	}
+
+	return list, err
+}
+
+// ———————————————————————————————————————————————————————————————
+// Moves and blocked clients
+// ———————————————————————————————————————————————————————————————
+
+// popEvent is the keyspace notification of a pop from the given end of a list
+func popEvent(direction actions.BlockingPopDirection) string {
+	if direction == actions.BLEFT {
+		return "lpop"
+	}
+	return "rpop"
+}
+
+// pushEvent is the keyspace notification of a push to the given end of a list
+func pushEvent(direction actions.BlockingPopDirection) string {
+	if direction == actions.BLEFT {
+		return "lpush"
+	}
+	return "rpush"
+}
+
+func (store *Store) addListWaiter(key string, client *objects.BlockingPopClient) {
+	// must be used with a lock
+	store.listWaiters[key] = append(store.listWaiters[key], client)
+}
+
+// nextListWaiter takes the longest waiting client off the queue of the key and marks it served
+func (store *Store) nextListWaiter(key string) *objects.BlockingPopClient {
+	// must be used with a lock
+	store.pruneListWaiters([]string{key})
+
+	waiters := store.listWaiters[key]
+	if len(waiters) == 0 {
+		return nil
+	}
+
+	client := waiters[0]
+	client.Serve()
+
+	if len(waiters) == 1 {
+		delete(store.listWaiters, key)
+	} else {
+		store.listWaiters[key] = waiters[1:]
+	}
+
+	return client
+}
+
+// pruneListWaiters drops the clients that were served by another key or gave up waiting
+func (store *Store) pruneListWaiters(keys []string) {
+	// must be used with a lock
+	for _, key := range keys {
+		waiters := store.listWaiters[key]
+
+		live := waiters[:0]
+		for _, client := range waiters {
+			if !client.IsDone() {
+				live = append(live, client)
+			}
+		}
+
+		if len(live) == 0 {
+			delete(store.listWaiters, key)
+		} else {
+			store.listWaiters[key] = live
+		}
+	}
+}
+
+// serveListClients hands the elements of a list to the clients blocked on its key in FIFO
+// order. BLMOVE clients push their element to the destination, which in turn serves the
+// clients blocked there. The dispersals are in the order the clients were served.
+func (store *Store) serveListClients(key string, list *objects.RedisList) []objects.BlockingPopDisperal {
+	// must be used with a lock
+	dispersals := []objects.BlockingPopDisperal{}
+	served := false
+
+	for list.GetSize() > 0 {
+		client := store.nextListWaiter(key)
+		if client == nil {
+			break
+		}
+
+		result := objects.ListPopResult{Key: key, Logged: true}
+		target := client.MoveTo()
+
+		if target == nil {
+			result.Values = list.PopN(client.Direction(), client.Count())
+			store.notify(pubsub.NotifyList, popEvent(client.Direction()), key)
+			served = true
+
+			dispersals = append(dispersals, objects.BlockingPopDisperal{
+				Channel: client.Channel(),
+				Value:   result,
+				Entry:   store.logEntry(listPopAction(client.Direction()), key, strconv.Itoa(len(result.Values))),
+			})
+			continue
+		}
+
+		destination, err := store.getOrCreateList(target.Key, actions.LMove)
+		if err != nil {
+			result.Err = err
+			dispersals = append(dispersals, objects.BlockingPopDisperal{Channel: client.Channel(), Value: result})
+			continue
+		}
+
+		value := list.Pop(client.Direction())
+		destination.Push(target.Direction, value)
+		result.Values = []string{value}
+		store.keyModified(target.Key)
+		store.notify(pubsub.NotifyList, popEvent(client.Direction()), key)
+		store.notify(pubsub.NotifyList, pushEvent(target.Direction), target.Key)
+		served = true
+
+		dispersals = append(dispersals, objects.BlockingPopDisperal{
+			Channel: client.Channel(),
+			Value:   result,
+			Entry: store.logEntry(actions.LMove, key, target.Key,
+				listDirectionName(client.Direction()), listDirectionName(target.Direction)),
+		})
+
+		// the clients blocked on the destination are served after the move that fed them
+		if target.Key != key {
+			dispersals = append(dispersals, store.serveListClients(target.Key, destination)...)
+		}
+	}
+
+	if served && list.IsEmpty() {
+		store.notify(pubsub.NotifyGeneric, "del", key)
+	}
+	store.deleteListIfEmpty(key, list)
+
+	return dispersals
+}
+
+// disperseToListClients sends the served clients their results, the pops are logged
+// with the command the view runs
+func (store *Store) disperseToListClients(dispersals []objects.BlockingPopDisperal) {
+	for _, dispersal := range dispersals {
+		if dispersal.Entry != nil {
+			store.logServed(dispersal.Entry)
+		}
+		dispersal.Channel <- dispersal.Value
+	}
+}
+
+func listPopAction(direction actions.BlockingPopDirection) actions.Action {
+	if direction == actions.BLEFT {
+		return actions.LPop
+	}
+	return actions.RPop
+}
+
+func listPushAction(direction actions.BlockingPopDirection) actions.Action {
+	if direction == actions.BLEFT {
+		return actions.LPush
+	}
+	return actions.RPush
+}
+
+func listDirectionName(direction actions.BlockingPopDirection) string {
+	if direction == actions.BLEFT {
+		return "LEFT"
+	}
+	return "RIGHT"
+}
+
+// lmove pops from one end of source and pushes to one end of destination
+func (store *Store) lmove(source, destination string, from, to actions.BlockingPopDirection) (string, []objects.BlockingPopDisperal, error) {
+	// must be used with a lock
+	sourceList, err := store.getList(source, actions.LMove)
+	if err != nil {
+		return "", nil, err
+	}
+
+	destinationList, err := store.getOrCreateList(destination, actions.LMove)
+	if err != nil {
+		return "", nil, err
+	}
+
+	value := sourceList.Pop(from)
+	destinationList.Push(to, value)
+	store.keyModified(source)
+	store.keyModified(destination)
+	store.notifyRemoval(pubsub.NotifyList, popEvent(from), source, sourceList.IsEmpty())
+	store.notify(pubsub.NotifyList, pushEvent(to), destination)
+
+	store.deleteListIfEmpty(source, sourceList)
+
+	return value, store.serveListClients(destination, destinationList), nil
+}
+
+func (store *Store) LMove(source, destination string, from, to actions.BlockingPopDirection) (string, error) {
+	store.mu.Lock()
+	value, dispersals, err := store.lmove(source, destination, from, to)
+	store.mu.Unlock()
+
+	store.disperseToListClients(dispersals)
+
+	return value, err
+}
+
+// BLMove moves an element like LMove, waiting for source to receive one when it does not exist.
+// A zero timeout waits forever.
+func (store *Store) BLMove(ctx context.Context, source, destination string, from, to actions.BlockingPopDirection, timeout time.Duration) (objects.ListPopResult, error) {
+	store.mu.Lock()
+
+	value, dispersals, err := store.lmove(source, destination, from, to)
+	if err != errs.ErrNotFound {
+		store.mu.Unlock()
+		store.disperseToListClients(dispersals)
+
+		return objects.ListPopResult{Key: source, Values: []string{value}}, err
+	}
+
+	client := objects.NewBlockingPopClient(from, 1, &objects.ListMoveTarget{Key: destination, Direction: to})
+	store.addListWaiter(source, client)
+	store.mu.Unlock()
+
+	result, err := store.waitForListClient(ctx, client, []string{source}, timeout)
+	if err != nil {
+		return objects.ListPopResult{}, err
+	}
+
+	return result, result.Err
+}
+
+// lmpop pops up to count elements from the first non-empty list among keys
+func (store *Store) lmpop(keys []string, direction actions.BlockingPopDirection, count int, action actions.Action) (objects.ListPopResult, error) {
+	// must be used with a lock
+	for _, key := range keys {
+		list, err := store.getList(key, action)
+		if err == errs.ErrNotFound {
+			continue
+		}
+
+		if err != nil {
+			return objects.ListPopResult{}, err
+		}
+
+		values := list.PopN(direction, count)
+		store.keyModified(key)
+		store.notifyRemoval(pubsub.NotifyList, popEvent(direction), key, list.IsEmpty())
+		store.deleteListIfEmpty(key, list)
+
+		return objects.ListPopResult{Key: key, Values: values}, nil
+	}
+
+	return objects.ListPopResult{}, errs.ErrNotFound
+}
+
+func (store *Store) LMPop(keys []string, direction actions.BlockingPopDirection, count int) (objects.ListPopResult, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return store.lmpop(keys, direction, count, actions.LMPop)
+}
+
+// blockingPop pops from the first list among keys, or parks the client on all of them
+// until an element arrives. Clients are served in the order they blocked.
+// A zero timeout waits forever.
+func (store *Store) blockingPop(ctx context.Context, keys []string, direction actions.BlockingPopDirection, count int, timeout time.Duration, action actions.Action) (objects.ListPopResult, error) {
+	if direction != actions.BLEFT && direction != actions.BRIGHT {
+		return objects.ListPopResult{}, errors.New("INVALID POP")
+	}
+
+	store.mu.Lock()
+
+	// the lookup also rejects keys of another type before anything is parked
+	result, err := store.lmpop(keys, direction, count, action)
+	if err != errs.ErrNotFound {
+		store.mu.Unlock()
+		return result, err
+	}
+
+	// none of the keys exist, the client waits outside the keyspace
+	client := objects.NewBlockingPopClient(direction, count, nil)
+	for _, key := range keys {
+		store.addListWaiter(key, client)
+	}
+	store.mu.Unlock()
+
+	result, err = store.waitForListClient(ctx, client, keys, timeout)
+	if err != nil {
+		return objects.ListPopResult{}, err
+	}
+
+	return result, result.Err
+}
+
+func (store *Store) BLPop(ctx context.Context, keys []string, timeout time.Duration) (objects.ListPopResult, error) {
+	return store.blockingPop(ctx, keys, actions.BLEFT, 1, timeout, actions.BLPop)
+}
+
+func (store *Store) BRPop(ctx context.Context, keys []string, timeout time.Duration) (objects.ListPopResult, error) {
+	return store.blockingPop(ctx, keys, actions.BRIGHT, 1, timeout, actions.BRPop)
+}
+
+func (store *Store) BLMPop(ctx context.Context, keys []string, direction actions.BlockingPopDirection, count int, timeout time.Duration) (objects.ListPopResult, error) {
+	return store.blockingPop(ctx, keys, direction, count, timeout, actions.BLMPop)
+}
+
+// waitForListClient waits until a blocked client is served, its timeout passes or ctx is done,
+// the cause of ctx is returned in the last case
+func (store *Store) waitForListClient(ctx context.Context, client *objects.BlockingPopClient, keys []string, timeout time.Duration) (objects.ListPopResult, error) {
+	var timeoutChannel <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		timeoutChannel = timer.C
+	}
+
+	select {
+	case result := <-client.Channel():
+		// drop the client from the other keys it was parked on
+		store.mu.Lock()
+		store.pruneListWaiters(keys)
+		store.mu.Unlock()
+
+		return store.keepListResult(client, result, context.Cause(ctx))
+
+	case <-timeoutChannel:
+		return store.abandonListClient(client, keys, errs.Timeout)
+
+	case <-ctx.Done():
+		return store.abandonListClient(client, keys, context.Cause(ctx))
+	}
+}
+
+// abandonListClient stops a client from waiting. A dispersal may have raced the cancellation,
+// the client then keeps what it was served.
+func (store *Store) abandonListClient(client *objects.BlockingPopClient, keys []string, cause error) (objects.ListPopResult, error) {
+	store.mu.Lock()
+
+	if !client.IsDone() {
+		client.Cancel()
+		store.pruneListWaiters(keys)
+		store.mu.Unlock()
+
+		return objects.ListPopResult{}, cause
+	}
+	store.mu.Unlock()
+
+	// sent right after the store lock is released by whoever served the client
+	return store.keepListResult(client, <-client.Channel(), cause)
+}
+
+// keepListResult hands a served client its result, unless its connection is gone, in which
+// case the elements go back to the list so that they are not lost
+func (store *Store) keepListResult(client *objects.BlockingPopClient, result objects.ListPopResult, cause error) (objects.ListPopResult, error) {
+	// a move already landed in its destination and is kept, as logged with the command that served it
+	if cause != errs.ConnectionClosed || result.Err != nil || client.MoveTo() != nil {
+		return result, result.Err
+	}
+
+	store.mu.Lock()
+	dispersals := store.restoreListElements(result.Key, client.Direction(), result.Values)
+	store.mu.Unlock()
+
+	store.disperseToListClients(dispersals)
+
+	return objects.ListPopResult{}, cause
+}
+
+// restoreListElements pushes popped elements back to the end they were taken from,
+// in their original order, and serves the clients now blocked on the list
+func (store *Store) restoreListElements(key string, direction actions.BlockingPopDirection, values []string) []objects.BlockingPopDisperal {
+	// must be used with a lock
+	list, err := store.getOrCreateList(key, actions.LPush)
+	if err != nil {
+		// the key now holds another type, there is nowhere to put the elements back
+		return nil
+	}
+
+	// pushed one by one from the last, like a push of the reversed values
+	reversed := make([]string, 0, len(values)+1)
+	reversed = append(reversed, key)
+	for i := len(values) - 1; i >= 0; i-- {
+		list.Push(direction, values[i])
+		reversed = append(reversed, values[i])
+	}
+	store.keyModified(key)
+	store.notify(pubsub.NotifyList, pushEvent(direction), key)
+
+	// the pop was logged by the command that served the client
+	store.logServed(store.logEntry(listPushAction(direction), reversed...))
+
+	return store.serveListClients(key, list)
+}
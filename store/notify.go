@@ -0,0 +1,24 @@
+package store
+
+import "server/store/pubsub"
+
+// ———————————————————————————————————————————————————————————————
+// Keyspace notifications
+// ———————————————————————————————————————————————————————————————
+
+// notify publishes the keyspace notification of an event on a key of this database,
+// it is filtered by the classes enabled with notify-keyspace-events
+func (store *Store) notify(class pubsub.EventClass, event, key string) {
+	// must be used with a lock
+	store.notifier.Notify(class, event, key, store.index)
+}
+
+// notifyRemoval notifies event, followed by del when the removal left the key empty
+func (store *Store) notifyRemoval(class pubsub.EventClass, event, key string, emptied bool) {
+	// must be used with a lock
+	store.notify(class, event, key)
+
+	if emptied {
+		store.notify(pubsub.NotifyGeneric, "del", key)
+	}
+}
@@ -0,0 +1,169 @@
+package objects
+
+import (
+	"hash/fnv"
+	"iter"
+	"math/bits"
+)
+
+// Dict is a map from names that can be walked with a cursor, the keyspace and the hashes,
+// sets and sorted sets keep their names in one. Next to the map every name sits in a bucket
+// picked by its hash, and a scan call returns whole buckets in reverse binary order of their
+// index, like redis dictScan. That order survives the bucket count doubling or halving
+// between calls, so a name present during the whole iteration is returned at least once.
+
+const DICT_MIN_BUCKETS = 4
+
+// DICT_SCAN_EMPTY_VISITS bounds the empty buckets a scan call visits per name asked for
+const DICT_SCAN_EMPTY_VISITS = 10
+
+type dictEntry[V any] struct {
+	value V
+	hash  uint64
+	slot  int // index in its bucket
+}
+
+type Dict[V any] struct {
+	entries map[string]dictEntry[V]
+	buckets [][]string // a power of two of them, allocated with the first name
+}
+
+func NewDict[V any]() *Dict[V] {
+	return &Dict[V]{entries: make(map[string]dictEntry[V])}
+}
+
+func dictHash(name string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return h.Sum64()
+}
+
+func (d *Dict[V]) Len() int {
+	return len(d.entries)
+}
+
+func (d *Dict[V]) Get(name string) (V, bool) {
+	entry, exists := d.entries[name]
+	return entry.value, exists
+}
+
+func (d *Dict[V]) Set(name string, value V) {
+	if entry, exists := d.entries[name]; exists {
+		entry.value = value
+		d.entries[name] = entry
+		return
+	}
+
+	if len(d.entries) >= len(d.buckets) {
+		d.resize(max(2*len(d.buckets), DICT_MIN_BUCKETS))
+	}
+
+	hash := dictHash(name)
+	d.entries[name] = dictEntry[V]{value: value, hash: hash, slot: d.place(name, hash)}
+}
+
+// Delete removes the name, reporting whether it was there
+func (d *Dict[V]) Delete(name string) bool {
+	entry, exists := d.entries[name]
+	if !exists {
+		return false
+	}
+
+	delete(d.entries, name)
+
+	// the last name of the bucket takes the freed slot
+	index := d.bucketIndex(entry.hash)
+	bucket := d.buckets[index]
+	last := len(bucket) - 1
+	if entry.slot != last {
+		moved := bucket[last]
+		bucket[entry.slot] = moved
+
+		movedEntry := d.entries[moved]
+		movedEntry.slot = entry.slot
+		d.entries[moved] = movedEntry
+	}
+	bucket[last] = ""
+	d.buckets[index] = bucket[:last]
+
+	if len(d.buckets) > DICT_MIN_BUCKETS && len(d.entries) < len(d.buckets)/8 {
+		d.resize(len(d.buckets) / 2)
+	}
+
+	return true
+}
+
+// All iterates over the names and their values in no particular order, the current
+// name may be deleted meanwhile
+func (d *Dict[V]) All() iter.Seq2[string, V] {
+	return func(yield func(string, V) bool) {
+		for name, entry := range d.entries {
+			if !yield(name, entry.value) {
+				return
+			}
+		}
+	}
+}
+
+func (d *Dict[V]) Names() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for name := range d.entries {
+			if !yield(name) {
+				return
+			}
+		}
+	}
+}
+
+func (d *Dict[V]) Clone() *Dict[V] {
+	clone := NewDict[V]()
+	for name, entry := range d.entries {
+		clone.Set(name, entry.value)
+	}
+
+	return clone
+}
+
+// Scan returns the names of the buckets from cursor on until about count names are
+// collected, with the cursor to continue from, 0 once every bucket was visited
+func (d *Dict[V]) Scan(cursor uint64, count int) ([]string, uint64) {
+	names := []string{}
+	if len(d.entries) == 0 {
+		return names, 0
+	}
+
+	count = max(count, 1)
+	mask := uint64(len(d.buckets) - 1)
+
+	for visits := count * DICT_SCAN_EMPTY_VISITS; visits > 0; visits-- {
+		names = append(names, d.buckets[cursor&mask]...)
+
+		// increment the reversed index, the bits above the mask are set so that the carry
+		// runs out of them and the cursor wraps to 0 after the last bucket
+		cursor = bits.Reverse64(bits.Reverse64(cursor|^mask) + 1)
+		if cursor == 0 || len(names) >= count {
+			break
+		}
+	}
+
+	return names, cursor
+}
+
+func (d *Dict[V]) bucketIndex(hash uint64) int {
+	return int(hash & uint64(len(d.buckets)-1))
+}
+
+// place appends the name to its bucket and returns its slot there
+func (d *Dict[V]) place(name string, hash uint64) int {
+	index := d.bucketIndex(hash)
+	d.buckets[index] = append(d.buckets[index], name)
+	return len(d.buckets[index]) - 1
+}
+
+func (d *Dict[V]) resize(size int) {
+	d.buckets = make([][]string, size)
+	for name, entry := range d.entries {
+		entry.slot = d.place(name, entry.hash)
+		d.entries[name] = entry
+	}
+}
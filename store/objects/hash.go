@@ -1,45 +1,257 @@
 package objects
 
-import "server/errs"
+import (
+	"container/heap"
+	"iter"
+	"math/rand/v2"
+	"server/errs"
+	"time"
+)
 
-type HashSet map[string]string
+// HashSet hides a field once its TTL passed, the cleanup deletes it later on. The field
+// TTLs are also kept in a heap so that counting the hidden fields only visits them.
+type HashSet struct {
+	fields   *Dict[string]
+	expiries map[string]*fieldExpiry // only fields with a TTL have an entry
+	byTime   fieldExpiryHeap
+}
+
+type fieldExpiry struct {
+	field string
+	at    time.Time
+	index int // position in the heap
+}
+
+func NewHashSet() *HashSet {
+	return &HashSet{
+		fields:   NewDict[string](),
+		expiries: make(map[string]*fieldExpiry),
+	}
+}
 
-func NewHashSet() HashSet {
-	return make(HashSet)
+// Set writes the field and, like HSET, drops its TTL
+func (hs *HashSet) Set(key, value string) {
+	hs.fields.Set(key, value)
+	hs.PersistField(key)
 }
 
-func (hs HashSet) Set(key, value string) {
-	hs[key] = value
+// Update overwrites the value of a field but keeps its TTL, an expired field starts over without one
+func (hs *HashSet) Update(key, value string) {
+	if hs.hasExpired(key) {
+		hs.PersistField(key)
+	}
+	hs.fields.Set(key, value)
 }
 
-func (hs HashSet) Get(key string) (string, bool) {
-	value, exists := hs[key]
-	return value, exists
+func (hs *HashSet) Get(key string) (string, bool) {
+	value, exists := hs.fields.Get(key)
+	if !exists || hs.hasExpired(key) {
+		return "", false
+	}
+
+	return value, true
 }
 
-func (hs HashSet) Exists(key string) bool {
-	_, exists := hs[key]
+func (hs *HashSet) Exists(key string) bool {
+	_, exists := hs.Get(key)
 	return exists
 }
 
-func (hs HashSet) Delete(keys []string) int {
+func (hs *HashSet) Delete(keys []string) int {
 	count := 0
 	for _, key := range keys {
 		_, exists := hs.Get(key)
 		if exists {
 			count++
 		}
-		delete(hs, key)
+		hs.fields.Delete(key)
+		hs.PersistField(key)
 	}
 
 	return count
 }
 
-func ValidateObjectAsHash(object *Object) (HashSet, error) {
-	hs, ok := object.Data.(HashSet)
+func (hs *HashSet) Len() int {
+	return hs.fields.Len() - hs.byTime.countExpired(0, time.Now())
+}
+
+// All iterates over the field value pairs
+func (hs *HashSet) All() iter.Seq2[string, string] {
+	return func(yield func(string, string) bool) {
+		for field, value := range hs.fields.All() {
+			if hs.hasExpired(field) {
+				continue
+			}
+
+			if !yield(field, value) {
+				return
+			}
+		}
+	}
+}
+
+// Scan returns the next batch of fields, see Dict.Scan
+func (hs *HashSet) Scan(cursor uint64, count int) ([]string, uint64) {
+	batch, next := hs.fields.Scan(cursor, count)
+
+	fields := batch[:0]
+	for _, field := range batch {
+		if !hs.hasExpired(field) {
+			fields = append(fields, field)
+		}
+	}
+
+	return fields, next
+}
+
+func (hs *HashSet) Fields() []string {
+	fields := make([]string, 0, hs.fields.Len())
+	for field := range hs.All() {
+		fields = append(fields, field)
+	}
+
+	return fields
+}
+
+// RandomFields picks count distinct fields, or every field when the hash is smaller.
+// A negative count picks -count fields that may repeat.
+func (hs *HashSet) RandomFields(count int) []string {
+	fields := hs.Fields()
+
+	if len(fields) == 0 {
+		return fields
+	}
+
+	if count < 0 {
+		picked := make([]string, -count)
+		for i := range picked {
+			picked[i] = fields[rand.IntN(len(fields))]
+		}
+		return picked
+	}
+
+	count = min(count, len(fields))
+
+	// partial Fisher-Yates shuffle
+	for i := 0; i < count; i++ {
+		j := i + rand.IntN(len(fields)-i)
+		fields[i], fields[j] = fields[j], fields[i]
+	}
+
+	return fields[:count]
+}
+
+// ——————————————————————————————————————————————————————————————————
+// Field expiry
+// ——————————————————————————————————————————————————————————————————
+
+func (hs *HashSet) ExpireFieldAt(key string, at time.Time) {
+	if expiry, exists := hs.expiries[key]; exists {
+		expiry.at = at
+		heap.Fix(&hs.byTime, expiry.index)
+		return
+	}
+
+	expiry := &fieldExpiry{field: key, at: at}
+	hs.expiries[key] = expiry
+	heap.Push(&hs.byTime, expiry)
+}
+
+// FieldExpiry returns when the field expires, false when it has no TTL
+func (hs *HashSet) FieldExpiry(key string) (time.Time, bool) {
+	expiry, exists := hs.expiries[key]
+	if !exists {
+		return time.Time{}, false
+	}
+
+	return expiry.at, true
+}
+
+// PersistField removes the TTL of the field, reporting whether it had one
+func (hs *HashSet) PersistField(key string) bool {
+	expiry, exists := hs.expiries[key]
+	if !exists {
+		return false
+	}
+
+	delete(hs.expiries, key)
+	heap.Remove(&hs.byTime, expiry.index)
+	return true
+}
+
+// hasExpired reports whether the TTL of the field passed, the field is hidden from then on
+func (hs *HashSet) hasExpired(key string) bool {
+	expiry, exists := hs.expiries[key]
+	return exists && time.Now().After(expiry.at)
+}
+
+// FieldExpiries iterates over the fields that have a TTL and when they expire
+func (hs *HashSet) FieldExpiries() iter.Seq2[string, time.Time] {
+	return func(yield func(string, time.Time) bool) {
+		for key, expiry := range hs.expiries {
+			if !yield(key, expiry.at) {
+				return
+			}
+		}
+	}
+}
+
+// Copy returns a hash with the same fields and field TTLs
+func (hs *HashSet) Copy() *HashSet {
+	copied := &HashSet{
+		fields:   hs.fields.Clone(),
+		expiries: make(map[string]*fieldExpiry, len(hs.expiries)),
+	}
+
+	for key, expiry := range hs.expiries {
+		copied.ExpireFieldAt(key, expiry.at)
+	}
+
+	return copied
+}
+
+// fieldExpiryHeap orders the field TTLs by when they expire, soonest first
+type fieldExpiryHeap []*fieldExpiry
+
+func (h fieldExpiryHeap) Len() int           { return len(h) }
+func (h fieldExpiryHeap) Less(i, j int) bool { return h[i].at.Before(h[j].at) }
+
+func (h fieldExpiryHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *fieldExpiryHeap) Push(x any) {
+	expiry := x.(*fieldExpiry)
+	expiry.index = len(*h)
+	*h = append(*h, expiry)
+}
+
+func (h *fieldExpiryHeap) Pop() any {
+	old := *h
+	n := len(old)
+	expiry := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return expiry
+}
+
+// countExpired counts the TTLs under index i that passed, a subtree whose root is still
+// to come holds none of them
+func (h fieldExpiryHeap) countExpired(i int, now time.Time) int {
+	if i >= len(h) || !now.After(h[i].at) {
+		return 0
+	}
+
+	return 1 + h.countExpired(2*i+1, now) + h.countExpired(2*i+2, now)
+}
+
+func ValidateObjectAsHash(object *Object) (*HashSet, error) {
+	hs, ok := object.Data.(*HashSet)
 	if !ok {
 		return nil, errs.TypeMismatch
 	}
 
 	return hs, nil
-}
\ No newline at end of file
+}
@@ -9,6 +9,8 @@ const (
 	List   DataType = "list"
 	Hash DataType = "hash"
 	Set DataType = "set"
+	ZSet DataType = "zset"
+	Stream DataType = "stream"
 )
 
 type Object struct {
@@ -28,6 +30,30 @@ func NewObject(dataType DataType, data any) *Object {
 	}
 }
 
+// Copy returns a deep copy of the object with the same expiry
+func (object *Object) Copy() *Object {
+	copied := NewObject(object.DataType, object.Data)
+
+	switch data := object.Data.(type) {
+	case *RedisList:
+		copied.Data = data.Copy()
+	case *HashSet:
+		copied.Data = data.Copy()
+	case RedisSet:
+		copied.Data = data.Copy()
+	case *SortedSet:
+		copied.Data = data.Copy()
+	case *RedisStream:
+		copied.Data = data.Copy()
+	}
+
+	if object.expiry != nil {
+		copied.expiry = &Expiry{At: object.expiry.At}
+	}
+
+	return copied
+}
+
 func (object *Object) HasExpired() bool {
 	return object.expiry != nil && time.Now().After(object.expiry.At)
 }
@@ -43,14 +69,40 @@ func (object *Object) Expire(seconds int64) {
 	}
 }
 
+func (object *Object) ExpireAt(at time.Time) {
+	if object.expiry != nil {
+		object.expiry.At = at
+	} else {
+		object.expiry = &Expiry{
+			At: at,
+		}
+	}
+}
+
+func (object *Object) Persist() {
+	object.expiry = nil
+}
+
 func (object *Object) GetExpiry() *Expiry {
 	return object.expiry
 }
 
+// TTL returns the remaining time to live in seconds, rounded to the nearest second,
+// or -1 when the object does not expire
 func (object *Object) TTL() int {
 	if object.expiry == nil {
 		return -1
 	}
 
-	return int(time.Until(object.expiry.At).Seconds())
-}
\ No newline at end of file
+	return int((object.PTTL() + 500) / 1000)
+}
+
+// PTTL returns the remaining time to live in milliseconds, or -1 when the object does not expire
+func (object *Object) PTTL() int64 {
+	if object.expiry == nil {
+		return -1
+	}
+
+	// computed from unix milliseconds, time.Until saturates for far away expiries
+	return max(object.expiry.At.UnixMilli()-time.Now().UnixMilli(), 0)
+}
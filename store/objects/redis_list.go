@@ -2,6 +2,8 @@ package objects
 
 import (
 	"errors"
+	"server/commands"
+	"server/errs"
 	"server/store/actions"
 )
 
@@ -92,22 +94,120 @@ func (c *Chunk) PopFront() (string, error) {
 	return element, nil
 }
 
+// Get returns the element at the offset i from the head of the chunk
+func (c *Chunk) Get(i int) string {
+	return c.elements[(c.head+i)%CHUNK_LENGTH]
+}
+
+func (c *Chunk) Set(i int, value string) {
+	slot := (c.head + i) % CHUNK_LENGTH
+	c.byteSize += len(value) - len(c.elements[slot])
+	c.elements[slot] = value
+}
+
+// InsertAt places value at offset i, shifting the following elements to the right
+func (c *Chunk) InsertAt(i int, value string) {
+	if c.size == CHUNK_LENGTH {
+		panic("chunk full, cannot add more")
+	}
+
+	for j := c.size; j > i; j-- {
+		c.elements[(c.head+j)%CHUNK_LENGTH] = c.elements[(c.head+j-1)%CHUNK_LENGTH]
+	}
+	c.elements[(c.head+i)%CHUNK_LENGTH] = value
+
+	c.byteSize += len(value)
+	c.size++
+}
+
+// RemoveAt deletes the element at offset i, shifting the following elements to the left
+func (c *Chunk) RemoveAt(i int) string {
+	element := c.Get(i)
+
+	for j := i; j < c.size-1; j++ {
+		c.elements[(c.head+j)%CHUNK_LENGTH] = c.elements[(c.head+j+1)%CHUNK_LENGTH]
+	}
+	c.elements[(c.head+c.size-1)%CHUNK_LENGTH] = ""
+
+	c.byteSize -= len(element)
+	c.size--
+
+	return element
+}
+
+// ListMoveTarget is where BLMOVE pushes the element it pops
+type ListMoveTarget struct {
+	Key       string
+	Direction actions.BlockingPopDirection
+}
+
+type ListPopResult struct {
+	Key    string
+	Values []string
+	Err    error
+
+	Logged bool // served while blocked, the command serving the client logged the pop
+}
+
+// BlockingPopClient waits for an element to be pushed to a list, it may wait on
+// several keys at once. All access happens under the store lock.
 type BlockingPopClient struct {
-	channel chan string
+	channel chan ListPopResult
 	direction actions.BlockingPopDirection
+	count int // elements popped when served, BLMPOP may take more than one
+	moveTo *ListMoveTarget // set for BLMOVE
+	done bool // served, or given up waiting
 }
 
 type BlockingPopDisperal struct {
-	Channel chan string
-	Value string
+	Channel chan ListPopResult
+	Value ListPopResult
+	Entry *commands.RedisCommand // the pop as the AOF logs it, nil when nothing was popped
+}
+
+func NewBlockingPopClient(direction actions.BlockingPopDirection, count int, moveTo *ListMoveTarget) *BlockingPopClient {
+	return &BlockingPopClient{
+		// buffered so that a dispersal never blocks on a client that stopped listening
+		channel: make(chan ListPopResult, 1),
+		direction: direction,
+		count: count,
+		moveTo: moveTo,
+	}
+}
+
+func (client *BlockingPopClient) Channel() chan ListPopResult {
+	return client.channel
+}
+
+func (client *BlockingPopClient) Direction() actions.BlockingPopDirection {
+	return client.direction
+}
+
+func (client *BlockingPopClient) Count() int {
+	return client.count
+}
+
+func (client *BlockingPopClient) MoveTo() *ListMoveTarget {
+	return client.moveTo
+}
+
+func (client *BlockingPopClient) IsDone() bool {
+	return client.done
+}
+
+func (client *BlockingPopClient) Cancel() {
+	client.done = true
+}
+
+// Serve marks the client as taken by the key serving it, the other keys drop it
+func (client *BlockingPopClient) Serve() {
+	client.done = true
 }
 
 type RedisList struct {
 	size int
 	head *Chunk
 	tail *Chunk
-
-	blockingPopClients []*BlockingPopClient
 }
 
 func NewList() *RedisList {
@@ -120,18 +220,35 @@ func NewList() *RedisList {
 	}
 }
 
-func (rl *RedisList) LPush(values []string) []BlockingPopDisperal {
+// Copy returns a list with the same elements
+func (rl *RedisList) Copy() *RedisList {
+	list := NewList()
+	list.RPush(rl.Range(0, -1))
+
+	return list
+}
+
+func ValidateObjectAsList(object *Object) (*RedisList, error) {
+	rl, ok := object.Data.(*RedisList)
+	if !ok {
+		return nil, errs.TypeMismatch
+	}
+
+	return rl, nil
+}
+
+func (rl *RedisList) LPush(values []string) int {
 	for _, value := range values {
 		rl.lPushSingle(value)
 	}
-	return rl.getBlockingPopDispersal()
+	return rl.size
 }
 
-func (rl *RedisList) RPush(values []string) []BlockingPopDisperal {
+func (rl *RedisList) RPush(values []string) int {
 	for _, value := range values {
 		rl.rPushSingle(value)
 	}
-	return rl.getBlockingPopDispersal()
+	return rl.size
 }
 
 func (rl *RedisList) LPop() string {
@@ -164,15 +281,6 @@ func (rl *RedisList) RPop() string {
 	return el
 }
 
-func (rl *RedisList) AddBlockingPopClient(channel chan string, direction actions.BlockingPopDirection) {
-	client := &BlockingPopClient{
-		channel: channel,
-		direction: direction,
-	}
-
-	rl.blockingPopClients = append(rl.blockingPopClients, client)
-}
-
 func (rl *RedisList) IsEmpty() bool {
 	return rl.head == rl.tail && rl.head.IsEmpty()
 }
@@ -181,27 +289,31 @@ func (rl *RedisList) GetSize() int {
 	return rl.size
 }
 
-func (rl *RedisList) getBlockingPopDispersal() []BlockingPopDisperal {
-	iters := min(len(rl.blockingPopClients), rl.size)
-	blockingPopDispersals := make([]BlockingPopDisperal, iters)
+// Pop removes an element from the head for BLEFT or from the tail for BRIGHT
+func (rl *RedisList) Pop(direction actions.BlockingPopDirection) string {
+	if direction == actions.BLEFT {
+		return rl.LPop()
+	}
+	return rl.RPop()
+}
 
-	for i := range iters {
-		client := rl.blockingPopClients[i]
-		
-		if client.direction == actions.BLEFT {
-			blockingPopDispersals[i] = BlockingPopDisperal{
-				Channel: client.channel,
-				Value: rl.LPop(),
-			}
-		} else {
-			blockingPopDispersals[i] = BlockingPopDisperal{
-				Channel: client.channel,
-				Value: rl.RPop(),
-			}
-		}
+// PopN removes up to count elements from one end
+func (rl *RedisList) PopN(direction actions.BlockingPopDirection, count int) []string {
+	values := make([]string, min(count, rl.size))
+	for i := range values {
+		values[i] = rl.Pop(direction)
 	}
 
-	return blockingPopDispersals
+	return values
+}
+
+// Push adds an element at the head for BLEFT or at the tail for BRIGHT
+func (rl *RedisList) Push(direction actions.BlockingPopDirection, value string) {
+	if direction == actions.BLEFT {
+		rl.lPushSingle(value)
+	} else {
+		rl.rPushSingle(value)
+	}
 }
 
 func (rl *RedisList) lPushSingle(value string) int {
@@ -209,6 +321,7 @@ func (rl *RedisList) lPushSingle(value string) int {
 
 	if !head.CanPush(value) {
 		rl.expandHead()
+		head = rl.head
 	}
 
 	// add to the head
@@ -223,6 +336,7 @@ func (rl *RedisList) rPushSingle(value string) int {
 
 	if !tail.CanPush(value) {
 		rl.expandTail()
+		tail = rl.tail
 	}
 
 	tail.PushBack(value)
@@ -243,3 +357,369 @@ func (rl *RedisList) expandTail() {
 
 	rl.tail = chunk
 }
+
+// ———————————————————————————————————————————————————————————————
+// Index and range operations
+// ———————————————————————————————————————————————————————————————
+
+// normalizeIndex turns a possibly negative index into an offset from the head
+func (rl *RedisList) normalizeIndex(index int) (int, bool) {
+	if index < 0 {
+		index += rl.size
+	}
+
+	return index, index >= 0 && index < rl.size
+}
+
+// chunkAt finds the chunk holding the element at offset index, walking from the closer end
+// and skipping whole chunks by their size, and returns the offset inside that chunk
+func (rl *RedisList) chunkAt(index int) (*Chunk, int) {
+	if index < rl.size/2 {
+		chunk := rl.head
+		for index >= chunk.size {
+			index -= chunk.size
+			chunk = chunk.next
+		}
+		return chunk, index
+	}
+
+	chunk := rl.tail
+	fromTail := rl.size - 1 - index
+	for fromTail >= chunk.size {
+		fromTail -= chunk.size
+		chunk = chunk.prev
+	}
+	return chunk, chunk.size - 1 - fromTail
+}
+
+func (rl *RedisList) Index(index int) (string, bool) {
+	index, ok := rl.normalizeIndex(index)
+	if !ok {
+		return "", false
+	}
+
+	chunk, offset := rl.chunkAt(index)
+	return chunk.Get(offset), true
+}
+
+func (rl *RedisList) Set(index int, value string) bool {
+	index, ok := rl.normalizeIndex(index)
+	if !ok {
+		return false
+	}
+
+	chunk, offset := rl.chunkAt(index)
+	chunk.Set(offset, value)
+
+	return true
+}
+
+// Range returns the elements between the inclusive start and stop indices,
+// negative indices count from the tail
+func (rl *RedisList) Range(start, stop int) []string {
+	if start < 0 {
+		start += rl.size
+	}
+	if stop < 0 {
+		stop += rl.size
+	}
+	start = max(start, 0)
+	stop = min(stop, rl.size-1)
+
+	if start > stop {
+		return []string{}
+	}
+
+	items := make([]string, 0, stop-start+1)
+	chunk, offset := rl.chunkAt(start)
+
+	for len(items) < stop-start+1 {
+		if offset == chunk.size {
+			chunk = chunk.next
+			offset = 0
+			continue
+		}
+
+		items = append(items, chunk.Get(offset))
+		offset++
+	}
+
+	return items
+}
+
+// Insert places value before or after the first occurrence of pivot and returns the
+// new length, or -1 when pivot is not in the list
+func (rl *RedisList) Insert(pivot string, value string, before bool) int {
+	for chunk := rl.head; chunk != nil; chunk = chunk.next {
+		for i := 0; i < chunk.size; i++ {
+			if chunk.Get(i) != pivot {
+				continue
+			}
+
+			position := i
+			if !before {
+				position++
+			}
+
+			rl.insertAt(chunk, position, value)
+			return rl.size
+		}
+	}
+
+	return -1
+}
+
+// insertAt places value at the offset of a chunk, splitting the chunk when it is full
+func (rl *RedisList) insertAt(chunk *Chunk, position int, value string) {
+	rl.size++
+
+	if chunk.CanPush(value) {
+		chunk.InsertAt(position, value)
+		return
+	}
+
+	// the elements after the position move to a new chunk, the value then goes to
+	// whichever side has room, or to a chunk of its own in between
+	next := rl.splitChunk(chunk, position)
+
+	switch {
+	case chunk.CanPush(value):
+		chunk.PushBack(value)
+	case next.CanPush(value):
+		next.PushFront(value)
+	default:
+		middle := rl.insertChunkAfter(chunk)
+		middle.PushBack(value)
+	}
+}
+
+// splitChunk moves the elements from position on into a new chunk placed after chunk
+func (rl *RedisList) splitChunk(chunk *Chunk, position int) *Chunk {
+	next := rl.insertChunkAfter(chunk)
+
+	for chunk.size > position {
+		element, _ := chunk.PopBack()
+		next.PushFront(element)
+	}
+
+	return next
+}
+
+func (rl *RedisList) insertChunkAfter(chunk *Chunk) *Chunk {
+	next := NewChunk(chunk, chunk.next)
+
+	if chunk.next != nil {
+		chunk.next.prev = next
+	} else {
+		rl.tail = next
+	}
+	chunk.next = next
+
+	return next
+}
+
+// unlinkChunk removes an empty chunk from the list, the last remaining chunk is kept
+func (rl *RedisList) unlinkChunk(chunk *Chunk) {
+	if rl.head == rl.tail {
+		return
+	}
+
+	if chunk.prev != nil {
+		chunk.prev.next = chunk.next
+	} else {
+		rl.head = chunk.next
+	}
+
+	if chunk.next != nil {
+		chunk.next.prev = chunk.prev
+	} else {
+		rl.tail = chunk.prev
+	}
+}
+
+// mergeWithNext moves the elements of the following chunk into chunk when both fit in one
+func (rl *RedisList) mergeWithNext(chunk *Chunk) {
+	next := chunk.next
+	if next == nil || chunk.size+next.size > CHUNK_LENGTH || chunk.byteSize+next.byteSize >= CHUNK_SIZE_LIMIT {
+		return
+	}
+
+	for i := 0; i < next.size; i++ {
+		chunk.PushBack(next.Get(i))
+	}
+	next.size = 0
+
+	rl.unlinkChunk(next)
+}
+
+// Remove deletes up to count occurrences of value, from the head when count is positive,
+// from the tail when it is negative and all of them when it is zero
+func (rl *RedisList) Remove(count int, value string) int {
+	limit := count
+	if limit < 0 {
+		limit = -limit
+	}
+
+	removed := 0
+	touched := []*Chunk{}
+
+	if count >= 0 {
+		for chunk := rl.head; chunk != nil && (limit == 0 || removed < limit); chunk = chunk.next {
+			before := removed
+			for i := 0; i < chunk.size && (limit == 0 || removed < limit); {
+				if chunk.Get(i) == value {
+					chunk.RemoveAt(i)
+					removed++
+				} else {
+					i++
+				}
+			}
+
+			if removed > before {
+				touched = append(touched, chunk)
+			}
+		}
+	} else {
+		for chunk := rl.tail; chunk != nil && removed < limit; chunk = chunk.prev {
+			before := removed
+			for i := chunk.size - 1; i >= 0 && removed < limit; i-- {
+				if chunk.Get(i) == value {
+					chunk.RemoveAt(i)
+					removed++
+				}
+			}
+
+			if removed > before {
+				touched = append(touched, chunk)
+			}
+		}
+	}
+
+	rl.size -= removed
+
+	// drop the chunks that were emptied and merge the shrunk ones with their neighbours
+	for _, chunk := range touched {
+		if chunk.IsEmpty() {
+			rl.unlinkChunk(chunk)
+		}
+	}
+	for _, chunk := range touched {
+		if chunk.IsEmpty() {
+			continue
+		}
+		if chunk.prev != nil {
+			rl.mergeWithNext(chunk.prev)
+		}
+		if chunk.next != nil && !chunk.IsEmpty() {
+			rl.mergeWithNext(chunk)
+		}
+	}
+
+	return removed
+}
+
+// Trim keeps only the elements between the inclusive start and stop indices
+func (rl *RedisList) Trim(start, stop int) {
+	if start < 0 {
+		start += rl.size
+	}
+	if stop < 0 {
+		stop += rl.size
+	}
+	start = max(start, 0)
+	stop = min(stop, rl.size-1)
+
+	if start > stop {
+		rl.clear()
+		return
+	}
+
+	rl.dropFront(start)
+	rl.dropBack(rl.size - (stop - start + 1))
+}
+
+// dropFront removes n elements from the head, unlinking whole chunks where possible
+func (rl *RedisList) dropFront(n int) {
+	for n > 0 && rl.head.size <= n && rl.head.next != nil {
+		n -= rl.head.size
+		rl.size -= rl.head.size
+		rl.head = rl.head.next
+		rl.head.prev = nil
+	}
+
+	for ; n > 0; n-- {
+		rl.LPop()
+	}
+}
+
+// dropBack removes n elements from the tail, unlinking whole chunks where possible
+func (rl *RedisList) dropBack(n int) {
+	for n > 0 && rl.tail.size <= n && rl.tail.prev != nil {
+		n -= rl.tail.size
+		rl.size -= rl.tail.size
+		rl.tail = rl.tail.prev
+		rl.tail.next = nil
+	}
+
+	for ; n > 0; n-- {
+		rl.RPop()
+	}
+}
+
+func (rl *RedisList) clear() {
+	chunk := NewChunk(nil, nil)
+
+	rl.head = chunk
+	rl.tail = chunk
+	rl.size = 0
+}
+
+// Pos returns the indices of the elements equal to value. A positive rank starts at the
+// rank-th match from the head, a negative one walks from the tail. count 0 returns all
+// matches and maxLen 0 compares every element
+func (rl *RedisList) Pos(value string, rank int, count int, maxLen int) []int {
+	positions := []int{}
+
+	skip := rank - 1
+	if rank < 0 {
+		skip = -rank - 1
+	}
+
+	compared := 0
+	match := func(index int, element string) bool {
+		compared++
+		if element == value {
+			if skip > 0 {
+				skip--
+			} else {
+				positions = append(positions, index)
+			}
+		}
+
+		return (count > 0 && len(positions) == count) || (maxLen > 0 && compared == maxLen)
+	}
+
+	if rank > 0 {
+		index := 0
+		for chunk := rl.head; chunk != nil; chunk = chunk.next {
+			for i := 0; i < chunk.size; i++ {
+				if match(index, chunk.Get(i)) {
+					return positions
+				}
+				index++
+			}
+		}
+	} else {
+		index := rl.size - 1
+		for chunk := rl.tail; chunk != nil; chunk = chunk.prev {
+			for i := chunk.size - 1; i >= 0; i-- {
+				if match(index, chunk.Get(i)) {
+					return positions
+				}
+				index--
+			}
+		}
+	}
+
+	return positions
+}
@@ -0,0 +1,140 @@
+package objects
+
+import "server/errs"
+
+// RedisSet refers to its members like a map does, copies of it share them
+type RedisSet struct {
+	members *Dict[struct{}]
+}
+
+func NewRedisSet() RedisSet {
+	return RedisSet{members: NewDict[struct{}]()}
+}
+
+func (rs RedisSet) Add(members []string) int {
+	count := 0
+	for _, member := range members {
+		if !rs.Contains(member) {
+			count++
+		}
+		rs.members.Set(member, struct{}{})
+	}
+
+	return count
+}
+
+func (rs RedisSet) Remove(members []string) int {
+	count := 0
+	for _, member := range members {
+		if rs.Contains(member) {
+			count++
+		}
+		rs.members.Delete(member)
+	}
+
+	return count
+}
+
+func (rs RedisSet) Contains(member string) bool {
+	_, exists := rs.members.Get(member)
+	return exists
+}
+
+func (rs RedisSet) Members() []string {
+	members := make([]string, 0, rs.members.Len())
+	for member := range rs.members.Names() {
+		members = append(members, member)
+	}
+
+	return members
+}
+
+func (rs RedisSet) Size() int {
+	return rs.members.Len()
+}
+
+// Scan returns the next batch of members, see Dict.Scan
+func (rs RedisSet) Scan(cursor uint64, count int) ([]string, uint64) {
+	return rs.members.Scan(cursor, count)
+}
+
+func (rs RedisSet) Copy() RedisSet {
+	return RedisSet{members: rs.members.Clone()}
+}
+
+func ValidateObjectAsSet(object *Object) (RedisSet, error) {
+	rs, ok := object.Data.(RedisSet)
+	if !ok {
+		return RedisSet{}, errs.TypeMismatch
+	}
+
+	return rs, nil
+}
+
+// SetIntersection returns a new set holding the members present in every set.
+// An empty input or any empty set yields an empty result.
+func SetIntersection(sets []RedisSet) RedisSet {
+	result := NewRedisSet()
+	if len(sets) == 0 {
+		return result
+	}
+
+	// walk the smallest set, probing the rest
+	smallest := sets[0]
+	for _, rs := range sets[1:] {
+		if rs.Size() < smallest.Size() {
+			smallest = rs
+		}
+	}
+
+	for member := range smallest.members.Names() {
+		inAll := true
+		for _, rs := range sets {
+			if !rs.Contains(member) {
+				inAll = false
+				break
+			}
+		}
+
+		if inAll {
+			result.members.Set(member, struct{}{})
+		}
+	}
+
+	return result
+}
+
+func SetUnion(sets []RedisSet) RedisSet {
+	result := NewRedisSet()
+	for _, rs := range sets {
+		for member := range rs.members.Names() {
+			result.members.Set(member, struct{}{})
+		}
+	}
+
+	return result
+}
+
+// SetDifference returns the members of the first set that are in none of the others.
+func SetDifference(sets []RedisSet) RedisSet {
+	result := NewRedisSet()
+	if len(sets) == 0 {
+		return result
+	}
+
+	for member := range sets[0].members.Names() {
+		inOther := false
+		for _, rs := range sets[1:] {
+			if rs.Contains(member) {
+				inOther = true
+				break
+			}
+		}
+
+		if !inOther {
+			result.members.Set(member, struct{}{})
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,256 @@
+package objects
+
+import "math/rand"
+
+// Skiplist ordered by (score, member), with per-level spans so that
+// rank lookups are O(log n). Modelled after the classic redis zskiplist.
+
+const SKIPLIST_MAX_LEVEL = 32
+const SKIPLIST_P = 0.25
+
+type skipListLevel struct {
+	forward *skipListNode
+	span    int
+}
+
+type skipListNode struct {
+	member   string
+	score    float64
+	backward *skipListNode
+	level    []skipListLevel
+}
+
+type skipList struct {
+	header *skipListNode
+	tail   *skipListNode
+	length int
+	level  int
+}
+
+func newSkipListNode(level int, score float64, member string) *skipListNode {
+	return &skipListNode{
+		member: member,
+		score:  score,
+		level:  make([]skipListLevel, level),
+	}
+}
+
+func newSkipList() *skipList {
+	return &skipList{
+		header: newSkipListNode(SKIPLIST_MAX_LEVEL, 0, ""),
+		level:  1,
+	}
+}
+
+func randomSkipListLevel() int {
+	level := 1
+	for level < SKIPLIST_MAX_LEVEL && rand.Float64() < SKIPLIST_P {
+		level++
+	}
+	return level
+}
+
+// precedes reports whether the node sorts strictly before (score, member)
+func (node *skipListNode) precedes(score float64, member string) bool {
+	return node.score < score || (node.score == score && node.member < member)
+}
+
+func (sl *skipList) insert(score float64, member string) *skipListNode {
+	update := make([]*skipListNode, SKIPLIST_MAX_LEVEL)
+	rank := make([]int, SKIPLIST_MAX_LEVEL)
+
+	x := sl.header
+	for i := sl.level - 1; i >= 0; i-- {
+		if i < sl.level-1 {
+			rank[i] = rank[i+1]
+		}
+
+		for x.level[i].forward != nil && x.level[i].forward.precedes(score, member) {
+			rank[i] += x.level[i].span
+			x = x.level[i].forward
+		}
+		update[i] = x
+	}
+
+	level := randomSkipListLevel()
+	if level > sl.level {
+		for i := sl.level; i < level; i++ {
+			rank[i] = 0
+			update[i] = sl.header
+			update[i].level[i].span = sl.length
+		}
+		sl.level = level
+	}
+
+	x = newSkipListNode(level, score, member)
+	for i := 0; i < level; i++ {
+		x.level[i].forward = update[i].level[i].forward
+		update[i].level[i].forward = x
+
+		x.level[i].span = update[i].level[i].span - (rank[0] - rank[i])
+		update[i].level[i].span = (rank[0] - rank[i]) + 1
+	}
+
+	// untouched levels now span one more node
+	for i := level; i < sl.level; i++ {
+		update[i].level[i].span++
+	}
+
+	if update[0] != sl.header {
+		x.backward = update[0]
+	}
+
+	if x.level[0].forward != nil {
+		x.level[0].forward.backward = x
+	} else {
+		sl.tail = x
+	}
+
+	sl.length++
+	return x
+}
+
+func (sl *skipList) deleteNode(x *skipListNode, update []*skipListNode) {
+	for i := 0; i < sl.level; i++ {
+		if update[i].level[i].forward == x {
+			update[i].level[i].span += x.level[i].span - 1
+			update[i].level[i].forward = x.level[i].forward
+		} else {
+			update[i].level[i].span--
+		}
+	}
+
+	if x.level[0].forward != nil {
+		x.level[0].forward.backward = x.backward
+	} else {
+		sl.tail = x.backward
+	}
+
+	for sl.level > 1 && sl.header.level[sl.level-1].forward == nil {
+		sl.level--
+	}
+
+	sl.length--
+}
+
+func (sl *skipList) delete(score float64, member string) bool {
+	update := make([]*skipListNode, SKIPLIST_MAX_LEVEL)
+
+	x := sl.header
+	for i := sl.level - 1; i >= 0; i-- {
+		for x.level[i].forward != nil && x.level[i].forward.precedes(score, member) {
+			x = x.level[i].forward
+		}
+		update[i] = x
+	}
+
+	x = x.level[0].forward
+	if x != nil && x.score == score && x.member == member {
+		sl.deleteNode(x, update)
+		return true
+	}
+
+	return false
+}
+
+// getRank returns the 1-based rank of the element, 0 when it is absent
+func (sl *skipList) getRank(score float64, member string) int {
+	rank := 0
+
+	x := sl.header
+	for i := sl.level - 1; i >= 0; i-- {
+		for x.level[i].forward != nil &&
+			(x.level[i].forward.precedes(score, member) ||
+				(x.level[i].forward.score == score && x.level[i].forward.member == member)) {
+			rank += x.level[i].span
+			x = x.level[i].forward
+		}
+
+		if x != sl.header && x.score == score && x.member == member {
+			return rank
+		}
+	}
+
+	return 0
+}
+
+// getByRank returns the node at the 1-based rank, nil when out of range
+func (sl *skipList) getByRank(rank int) *skipListNode {
+	traversed := 0
+
+	x := sl.header
+	for i := sl.level - 1; i >= 0; i-- {
+		for x.level[i].forward != nil && traversed+x.level[i].span <= rank {
+			traversed += x.level[i].span
+			x = x.level[i].forward
+		}
+
+		if traversed == rank {
+			return x
+		}
+	}
+
+	return nil
+}
+
+func (sl *skipList) firstInScoreRange(r ScoreRange) *skipListNode {
+	x := sl.header
+	for i := sl.level - 1; i >= 0; i-- {
+		for x.level[i].forward != nil && !r.AboveMin(x.level[i].forward.score) {
+			x = x.level[i].forward
+		}
+	}
+
+	x = x.level[0].forward
+	if x == nil || !r.BelowMax(x.score) {
+		return nil
+	}
+
+	return x
+}
+
+func (sl *skipList) lastInScoreRange(r ScoreRange) *skipListNode {
+	x := sl.header
+	for i := sl.level - 1; i >= 0; i-- {
+		for x.level[i].forward != nil && r.BelowMax(x.level[i].forward.score) {
+			x = x.level[i].forward
+		}
+	}
+
+	if x == sl.header || !r.AboveMin(x.score) {
+		return nil
+	}
+
+	return x
+}
+
+func (sl *skipList) firstInLexRange(r LexRange) *skipListNode {
+	x := sl.header
+	for i := sl.level - 1; i >= 0; i-- {
+		for x.level[i].forward != nil && !r.AboveMin(x.level[i].forward.member) {
+			x = x.level[i].forward
+		}
+	}
+
+	x = x.level[0].forward
+	if x == nil || !r.BelowMax(x.member) {
+		return nil
+	}
+
+	return x
+}
+
+func (sl *skipList) lastInLexRange(r LexRange) *skipListNode {
+	x := sl.header
+	for i := sl.level - 1; i >= 0; i-- {
+		for x.level[i].forward != nil && r.BelowMax(x.level[i].forward.member) {
+			x = x.level[i].forward
+		}
+	}
+
+	if x == sl.header || !r.AboveMin(x.member) {
+		return nil
+	}
+
+	return x
+}
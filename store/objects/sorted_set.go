@@ -0,0 +1,457 @@
+package objects
+
+import (
+	"iter"
+	"math"
+	"server/commands"
+	"server/errs"
+	"server/store/actions"
+	"strconv"
+	"strings"
+)
+
+type ScoredMember struct {
+	Member string
+	Score  float64
+}
+
+// SortedSet keeps a member -> score dictionary for O(1) score lookups
+// and a skiplist for everything that needs ordering.
+type SortedSet struct {
+	dict *Dict[float64]
+	zsl  *skipList
+}
+
+func NewSortedSet() *SortedSet {
+	return &SortedSet{
+		dict: NewDict[float64](),
+		zsl:  newSkipList(),
+	}
+}
+
+func (zs *SortedSet) Len() int {
+	return zs.dict.Len()
+}
+
+// All iterates over the members and their scores in no particular order
+func (zs *SortedSet) All() iter.Seq2[string, float64] {
+	return zs.dict.All()
+}
+
+// Scan returns the next batch of members, see Dict.Scan
+func (zs *SortedSet) Scan(cursor uint64, count int) ([]string, uint64) {
+	return zs.dict.Scan(cursor, count)
+}
+
+// Copy returns a sorted set with the same members
+func (zs *SortedSet) Copy() *SortedSet {
+	copied := NewSortedSet()
+	for member, score := range zs.dict.All() {
+		copied.Add(member, score)
+	}
+
+	return copied
+}
+
+func (zs *SortedSet) Score(member string) (float64, bool) {
+	score, exists := zs.dict.Get(member)
+	return score, exists
+}
+
+// Add inserts the member or moves it to its new score, returns true when the member is new
+func (zs *SortedSet) Add(member string, score float64) bool {
+	current, exists := zs.dict.Get(member)
+
+	if exists {
+		if current != score {
+			zs.zsl.delete(current, member)
+			zs.zsl.insert(score, member)
+			zs.dict.Set(member, score)
+		}
+		return false
+	}
+
+	zs.zsl.insert(score, member)
+	zs.dict.Set(member, score)
+	return true
+}
+
+func (zs *SortedSet) Remove(member string) bool {
+	score, exists := zs.dict.Get(member)
+	if !exists {
+		return false
+	}
+
+	zs.zsl.delete(score, member)
+	zs.dict.Delete(member)
+	return true
+}
+
+// Rank returns the 0-based position of the member, counted from the highest score when reversed
+func (zs *SortedSet) Rank(member string, reverse bool) (int, bool) {
+	score, exists := zs.dict.Get(member)
+	if !exists {
+		return 0, false
+	}
+
+	rank := zs.zsl.getRank(score, member)
+	if reverse {
+		return zs.zsl.length - rank, true
+	}
+
+	return rank - 1, true
+}
+
+// RangeByRank returns the members between the inclusive 0-based start and stop indexes,
+// negative indexes count from the end
+func (zs *SortedSet) RangeByRank(start, stop int, reverse bool) []ScoredMember {
+	length := zs.Len()
+
+	start, stop, ok := normalizeRankRange(start, stop, length)
+	if !ok {
+		return []ScoredMember{}
+	}
+
+	var x *skipListNode
+	if reverse {
+		x = zs.zsl.getByRank(length - start)
+	} else {
+		x = zs.zsl.getByRank(start + 1)
+	}
+
+	return collectSkipListNodes(x, reverse, 0, stop-start+1, func(node *skipListNode) bool {
+		return true
+	})
+}
+
+// RangeByScore returns the members within the range, skipping offset of them
+// and returning at most count (a negative count means no limit)
+func (zs *SortedSet) RangeByScore(r ScoreRange, reverse bool, offset, count int) []ScoredMember {
+	if reverse {
+		return collectSkipListNodes(zs.zsl.lastInScoreRange(r), true, offset, count, func(node *skipListNode) bool {
+			return r.AboveMin(node.score)
+		})
+	}
+
+	return collectSkipListNodes(zs.zsl.firstInScoreRange(r), false, offset, count, func(node *skipListNode) bool {
+		return r.BelowMax(node.score)
+	})
+}
+
+// RangeByLex is only meaningful when every member shares the same score
+func (zs *SortedSet) RangeByLex(r LexRange, reverse bool, offset, count int) []ScoredMember {
+	if reverse {
+		return collectSkipListNodes(zs.zsl.lastInLexRange(r), true, offset, count, func(node *skipListNode) bool {
+			return r.AboveMin(node.member)
+		})
+	}
+
+	return collectSkipListNodes(zs.zsl.firstInLexRange(r), false, offset, count, func(node *skipListNode) bool {
+		return r.BelowMax(node.member)
+	})
+}
+
+func collectSkipListNodes(x *skipListNode, reverse bool, offset, count int, inRange func(node *skipListNode) bool) []ScoredMember {
+	result := []ScoredMember{}
+
+	step := func(node *skipListNode) *skipListNode {
+		if reverse {
+			return node.backward
+		}
+		return node.level[0].forward
+	}
+
+	for x != nil && offset > 0 && inRange(x) {
+		offset--
+		x = step(x)
+	}
+
+	for x != nil && count != 0 && inRange(x) {
+		result = append(result, ScoredMember{Member: x.member, Score: x.score})
+		count--
+		x = step(x)
+	}
+
+	return result
+}
+
+func normalizeRankRange(start, stop, length int) (int, int, bool) {
+	if start < 0 {
+		start += length
+	}
+	if stop < 0 {
+		stop += length
+	}
+	if start < 0 {
+		start = 0
+	}
+
+	if start > stop || start >= length {
+		return 0, 0, false
+	}
+
+	if stop >= length {
+		stop = length - 1
+	}
+
+	return start, stop, true
+}
+
+func ValidateObjectAsSortedSet(object *Object) (*SortedSet, error) {
+	zs, ok := object.Data.(*SortedSet)
+	if !ok {
+		return nil, errs.TypeMismatch
+	}
+
+	return zs, nil
+}
+
+// ———————————————————————————————————————————————————————————————
+// Score and lex ranges
+// ———————————————————————————————————————————————————————————————
+
+type ScoreRange struct {
+	Min          float64
+	Max          float64
+	MinExclusive bool
+	MaxExclusive bool
+}
+
+func (r ScoreRange) AboveMin(score float64) bool {
+	if r.MinExclusive {
+		return score > r.Min
+	}
+	return score >= r.Min
+}
+
+func (r ScoreRange) BelowMax(score float64) bool {
+	if r.MaxExclusive {
+		return score < r.Max
+	}
+	return score <= r.Max
+}
+
+// ParseScoreRange parses bounds such as "1.5", "(1.5", "-inf" and "+inf"
+func ParseScoreRange(min, max string) (ScoreRange, error) {
+	r := ScoreRange{}
+	var err error
+
+	r.Min, r.MinExclusive, err = parseScoreBound(min)
+	if err != nil {
+		return r, err
+	}
+
+	r.Max, r.MaxExclusive, err = parseScoreBound(max)
+	if err != nil {
+		return r, err
+	}
+
+	return r, nil
+}
+
+func parseScoreBound(bound string) (float64, bool, error) {
+	exclusive := strings.HasPrefix(bound, "(")
+	if exclusive {
+		bound = bound[1:]
+	}
+
+	score, err := ParseScore(bound)
+	if err != nil {
+		return 0, false, errs.InvalidScoreRange
+	}
+
+	return score, exclusive, nil
+}
+
+type LexBound struct {
+	Value     string
+	Exclusive bool
+	Infinity  int // -1 for "-", 1 for "+", 0 for a regular value
+}
+
+type LexRange struct {
+	Min LexBound
+	Max LexBound
+}
+
+func (r LexRange) AboveMin(member string) bool {
+	switch r.Min.Infinity {
+	case -1:
+		return true
+	case 1:
+		return false
+	}
+
+	if r.Min.Exclusive {
+		return member > r.Min.Value
+	}
+	return member >= r.Min.Value
+}
+
+func (r LexRange) BelowMax(member string) bool {
+	switch r.Max.Infinity {
+	case 1:
+		return true
+	case -1:
+		return false
+	}
+
+	if r.Max.Exclusive {
+		return member < r.Max.Value
+	}
+	return member <= r.Max.Value
+}
+
+// ParseLexRange parses bounds such as "[a", "(a", "-" and "+"
+func ParseLexRange(min, max string) (LexRange, error) {
+	r := LexRange{}
+	var err error
+
+	r.Min, err = parseLexBound(min)
+	if err != nil {
+		return r, err
+	}
+
+	r.Max, err = parseLexBound(max)
+	if err != nil {
+		return r, err
+	}
+
+	return r, nil
+}
+
+func parseLexBound(bound string) (LexBound, error) {
+	switch {
+	case bound == "-":
+		return LexBound{Infinity: -1}, nil
+	case bound == "+":
+		return LexBound{Infinity: 1}, nil
+	case strings.HasPrefix(bound, "["):
+		return LexBound{Value: bound[1:]}, nil
+	case strings.HasPrefix(bound, "("):
+		return LexBound{Value: bound[1:], Exclusive: true}, nil
+	default:
+		return LexBound{}, errs.InvalidLexRange
+	}
+}
+
+// ———————————————————————————————————————————————————————————————
+// Score helpers
+// ———————————————————————————————————————————————————————————————
+
+func ParseScore(s string) (float64, error) {
+	score, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(score) {
+		return 0, errs.NotAFloat
+	}
+
+	return score, nil
+}
+
+func FormatScore(score float64) string {
+	if math.IsInf(score, 1) {
+		return "inf"
+	}
+	if math.IsInf(score, -1) {
+		return "-inf"
+	}
+
+	abs := math.Abs(score)
+	if abs != 0 && (abs < 1e-5 || abs >= 1e17) {
+		return strconv.FormatFloat(score, 'g', -1, 64)
+	}
+
+	return strconv.FormatFloat(score, 'f', -1, 64)
+}
+
+// ———————————————————————————————————————————————————————————————
+// Pops and range removals
+// ———————————————————————————————————————————————————————————————
+
+// Pop removes up to count members from the lowest (or highest when max is set) end
+func (zs *SortedSet) Pop(count int, max bool) []ScoredMember {
+	if count <= 0 {
+		return []ScoredMember{}
+	}
+
+	popped := zs.RangeByRank(0, count-1, max)
+	zs.removeAll(popped)
+
+	return popped
+}
+
+func (zs *SortedSet) RemoveRangeByRank(start, stop int) int {
+	return zs.removeAll(zs.RangeByRank(start, stop, false))
+}
+
+func (zs *SortedSet) RemoveRangeByScore(r ScoreRange) int {
+	return zs.removeAll(zs.RangeByScore(r, false, 0, -1))
+}
+
+func (zs *SortedSet) RemoveRangeByLex(r LexRange) int {
+	return zs.removeAll(zs.RangeByLex(r, false, 0, -1))
+}
+
+func (zs *SortedSet) removeAll(members []ScoredMember) int {
+	for _, m := range members {
+		zs.zsl.delete(m.Score, m.Member)
+		zs.dict.Delete(m.Member)
+	}
+
+	return len(members)
+}
+
+// ———————————————————————————————————————————————————————————————
+// Blocking pops
+// ———————————————————————————————————————————————————————————————
+
+type ZSetPopResult struct {
+	Key    string
+	Member ScoredMember
+
+	Logged bool // served while blocked, the command serving the client logged the pop
+}
+
+// ZSetBlockingPopClient may wait on several keys at once, it is served by
+// whichever of them receives a member first. All access happens
+// under the store lock.
+type ZSetBlockingPopClient struct {
+	channel   chan ZSetPopResult
+	direction actions.BlockingPopDirection
+	done      bool // served, or given up waiting
+}
+
+type ZSetBlockingPopDispersal struct {
+	Channel chan ZSetPopResult
+	Value   ZSetPopResult
+	Entry   *commands.RedisCommand // the pop as the AOF logs it
+}
+
+func NewZSetBlockingPopClient(direction actions.BlockingPopDirection) *ZSetBlockingPopClient {
+	return &ZSetBlockingPopClient{
+		// buffered so that a dispersal never blocks on a client that stopped listening
+		channel:   make(chan ZSetPopResult, 1),
+		direction: direction,
+	}
+}
+
+func (client *ZSetBlockingPopClient) Channel() chan ZSetPopResult {
+	return client.channel
+}
+
+func (client *ZSetBlockingPopClient) Direction() actions.BlockingPopDirection {
+	return client.direction
+}
+
+func (client *ZSetBlockingPopClient) IsDone() bool {
+	return client.done
+}
+
+func (client *ZSetBlockingPopClient) Cancel() {
+	client.done = true
+}
+
+// Serve marks the client as taken by the key serving it, the other keys drop it
+func (client *ZSetBlockingPopClient) Serve() {
+	client.done = true
+}
@@ -0,0 +1,336 @@
+package objects
+
+import (
+	"errors"
+	"math"
+	"server/errs"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type StreamID struct {
+	Ms  uint64
+	Seq uint64
+}
+
+var MinStreamID = StreamID{Ms: 0, Seq: 0}
+var MaxStreamID = StreamID{Ms: math.MaxUint64, Seq: math.MaxUint64}
+
+func (id StreamID) String() string {
+	return strconv.FormatUint(id.Ms, 10) + "-" + strconv.FormatUint(id.Seq, 10)
+}
+
+func (id StreamID) Less(other StreamID) bool {
+	return id.Ms < other.Ms || (id.Ms == other.Ms && id.Seq < other.Seq)
+}
+
+func (id StreamID) IsZero() bool {
+	return id.Ms == 0 && id.Seq == 0
+}
+
+// Next returns the smallest ID greater than id, false on overflow
+func (id StreamID) Next() (StreamID, bool) {
+	if id.Seq < math.MaxUint64 {
+		return StreamID{Ms: id.Ms, Seq: id.Seq + 1}, true
+	}
+	if id.Ms < math.MaxUint64 {
+		return StreamID{Ms: id.Ms + 1, Seq: 0}, true
+	}
+	return id, false
+}
+
+// Prev returns the largest ID smaller than id, false on underflow
+func (id StreamID) Prev() (StreamID, bool) {
+	if id.Seq > 0 {
+		return StreamID{Ms: id.Ms, Seq: id.Seq - 1}, true
+	}
+	if id.Ms > 0 {
+		return StreamID{Ms: id.Ms - 1, Seq: math.MaxUint64}, true
+	}
+	return id, false
+}
+
+// ParseStreamID parses "ms-seq", or a bare "ms" in which case missingSeq is used as the sequence
+func ParseStreamID(s string, missingSeq uint64) (StreamID, error) {
+	msPart, seqPart, hasSeq := strings.Cut(s, "-")
+
+	ms, err := strconv.ParseUint(msPart, 10, 64)
+	if err != nil {
+		return StreamID{}, errs.InvalidStreamID
+	}
+
+	if !hasSeq {
+		return StreamID{Ms: ms, Seq: missingSeq}, nil
+	}
+
+	seq, err := strconv.ParseUint(seqPart, 10, 64)
+	if err != nil {
+		return StreamID{}, errs.InvalidStreamID
+	}
+
+	return StreamID{Ms: ms, Seq: seq}, nil
+}
+
+// ParseStreamRangeStart parses the start of an XRANGE: "-", an ID, or an exclusive "(ID"
+func ParseStreamRangeStart(s string) (StreamID, error) {
+	if s == "-" {
+		return MinStreamID, nil
+	}
+
+	if strings.HasPrefix(s, "(") {
+		id, err := ParseStreamID(s[1:], 0)
+		if err != nil {
+			return id, err
+		}
+
+		next, ok := id.Next()
+		if !ok {
+			return id, errs.InvalidStreamID
+		}
+		return next, nil
+	}
+
+	return ParseStreamID(s, 0)
+}
+
+// ParseStreamRangeEnd parses the end of an XRANGE: "+", an ID, or an exclusive "(ID"
+func ParseStreamRangeEnd(s string) (StreamID, error) {
+	if s == "+" {
+		return MaxStreamID, nil
+	}
+
+	if strings.HasPrefix(s, "(") {
+		id, err := ParseStreamID(s[1:], math.MaxUint64)
+		if err != nil {
+			return id, err
+		}
+
+		prev, ok := id.Prev()
+		if !ok {
+			return id, errs.InvalidStreamID
+		}
+		return prev, nil
+	}
+
+	return ParseStreamID(s, math.MaxUint64)
+}
+
+type StreamEntry struct {
+	ID     StreamID
+	Fields []string // field value pairs
+}
+
+type StreamTrimStrategy string
+
+const (
+	TrimMaxLen StreamTrimStrategy = "MAXLEN"
+	TrimMinID  StreamTrimStrategy = "MINID"
+)
+
+type StreamTrim struct {
+	Strategy    StreamTrimStrategy
+	MaxLen      int
+	MinID       StreamID
+	Approximate bool
+	Limit       int // at most this many entries are evicted, 0 means no limit
+}
+
+// RedisStream keeps its entries in ID order, IDs only ever grow
+type RedisStream struct {
+	entries []StreamEntry
+	lastID  StreamID
+
+	groups map[string]*StreamGroup
+}
+
+func NewRedisStream() *RedisStream {
+	return &RedisStream{
+		entries: []StreamEntry{},
+	}
+}
+
+// Copy returns a stream with the same entries and consumer groups
+func (rs *RedisStream) Copy() *RedisStream {
+	copied := &RedisStream{
+		entries: make([]StreamEntry, len(rs.entries)),
+		lastID:  rs.lastID,
+	}
+
+	for i, entry := range rs.entries {
+		copied.entries[i] = StreamEntry{ID: entry.ID, Fields: slices.Clone(entry.Fields)}
+	}
+
+	if rs.groups != nil {
+		copied.groups = make(map[string]*StreamGroup, len(rs.groups))
+		for name, group := range rs.groups {
+			copied.groups[name] = group.copy()
+		}
+	}
+
+	return copied
+}
+
+func (rs *RedisStream) Len() int {
+	return len(rs.entries)
+}
+
+func (rs *RedisStream) LastID() StreamID {
+	return rs.lastID
+}
+
+// Add appends an entry, the ID spec is "*", "ms-*" or a full "ms-seq"
+func (rs *RedisStream) Add(idSpec string, fields []string) (StreamID, error) {
+	id, err := rs.nextID(idSpec)
+	if err != nil {
+		return id, err
+	}
+
+	rs.entries = append(rs.entries, StreamEntry{ID: id, Fields: fields})
+	rs.lastID = id
+
+	return id, nil
+}
+
+func (rs *RedisStream) nextID(idSpec string) (StreamID, error) {
+	if idSpec == "*" {
+		ms := uint64(time.Now().UnixMilli())
+		if ms > rs.lastID.Ms {
+			return StreamID{Ms: ms, Seq: 0}, nil
+		}
+
+		next, ok := rs.lastID.Next()
+		if !ok {
+			return next, errs.StreamIDOverflow
+		}
+		return next, nil
+	}
+
+	if msPart, found := strings.CutSuffix(idSpec, "-*"); found {
+		ms, err := strconv.ParseUint(msPart, 10, 64)
+		if err != nil {
+			return StreamID{}, errs.InvalidStreamID
+		}
+
+		switch {
+		case ms > rs.lastID.Ms:
+			return StreamID{Ms: ms, Seq: 0}, nil
+		case ms == rs.lastID.Ms && rs.lastID.Seq < math.MaxUint64:
+			return StreamID{Ms: ms, Seq: rs.lastID.Seq + 1}, nil
+		default:
+			return StreamID{}, errs.StreamIDTooSmall
+		}
+	}
+
+	id, err := ParseStreamID(idSpec, 0)
+	if err != nil {
+		return id, err
+	}
+
+	if id.IsZero() {
+		return id, errors.New("THE ID SPECIFIED IN XADD MUST BE GREATER THAN 0-0")
+	}
+
+	if !rs.lastID.Less(id) {
+		return id, errs.StreamIDTooSmall
+	}
+
+	return id, nil
+}
+
+// search returns the index of the first entry with an ID >= id
+func (rs *RedisStream) search(id StreamID) int {
+	return sort.Search(len(rs.entries), func(i int) bool {
+		return !rs.entries[i].ID.Less(id)
+	})
+}
+
+// Range returns the entries with start <= ID <= end, at most count of them
+// when count is positive
+func (rs *RedisStream) Range(start, end StreamID, count int, reverse bool) []StreamEntry {
+	result := []StreamEntry{}
+
+	if end.Less(start) {
+		return result
+	}
+
+	from := rs.search(start)
+	to := len(rs.entries)
+	if next, ok := end.Next(); ok {
+		to = rs.search(next)
+	}
+
+	if reverse {
+		for i := to - 1; i >= from && (count <= 0 || len(result) < count); i-- {
+			result = append(result, rs.entries[i])
+		}
+		return result
+	}
+
+	for i := from; i < to && (count <= 0 || len(result) < count); i++ {
+		result = append(result, rs.entries[i])
+	}
+	return result
+}
+
+// After returns the entries with an ID strictly greater than id
+func (rs *RedisStream) After(id StreamID, count int) []StreamEntry {
+	start, ok := id.Next()
+	if !ok {
+		return []StreamEntry{}
+	}
+
+	return rs.Range(start, MaxStreamID, count, false)
+}
+
+func (rs *RedisStream) Delete(ids []StreamID) int {
+	count := 0
+
+	for _, id := range ids {
+		i := rs.search(id)
+		if i >= len(rs.entries) || rs.entries[i].ID != id {
+			continue
+		}
+
+		rs.entries = append(rs.entries[:i], rs.entries[i+1:]...)
+		count++
+	}
+
+	return count
+}
+
+// Trim evicts entries from the head of the stream and returns how many were removed.
+// Approximate trimming is done exactly, it only enables the LIMIT cap.
+func (rs *RedisStream) Trim(trim StreamTrim) int {
+	evict := 0
+
+	switch trim.Strategy {
+	case TrimMaxLen:
+		evict = max(len(rs.entries)-trim.MaxLen, 0)
+	case TrimMinID:
+		evict = rs.search(trim.MinID)
+	}
+
+	if trim.Limit > 0 {
+		evict = min(evict, trim.Limit)
+	}
+
+	if evict == 0 {
+		return 0
+	}
+
+	rs.entries = append([]StreamEntry{}, rs.entries[evict:]...)
+
+	return evict
+}
+
+func ValidateObjectAsStream(object *Object) (*RedisStream, error) {
+	rs, ok := object.Data.(*RedisStream)
+	if !ok {
+		return nil, errs.TypeMismatch
+	}
+
+	return rs, nil
+}
@@ -0,0 +1,471 @@
+package objects
+
+import (
+	"server/errs"
+	"sort"
+	"time"
+)
+
+// ———————————————————————————————————————————————————————————————
+// Consumer groups
+// ———————————————————————————————————————————————————————————————
+
+// StreamPendingEntry is an entry delivered to a consumer but not acknowledged yet
+type StreamPendingEntry struct {
+	ID            StreamID
+	Consumer      string
+	DeliveryTime  time.Time
+	DeliveryCount int
+}
+
+func (pe *StreamPendingEntry) Idle(now time.Time) time.Duration {
+	return max(now.Sub(pe.DeliveryTime), 0)
+}
+
+type StreamConsumer struct {
+	Name     string
+	SeenTime time.Time
+	pending  map[StreamID]*StreamPendingEntry
+}
+
+// StreamGroup tracks the last delivered ID and the pending entries list (PEL),
+// kept sorted by ID
+type StreamGroup struct {
+	Name            string
+	LastDeliveredID StreamID
+
+	pending   []*StreamPendingEntry
+	consumers map[string]*StreamConsumer
+}
+
+type StreamPendingSummary struct {
+	Count     int
+	MinID     StreamID
+	MaxID     StreamID
+	Consumers []StreamConsumerPending
+}
+
+type StreamConsumerPending struct {
+	Consumer string
+	Count    int
+}
+
+type StreamPendingQuery struct {
+	Start    StreamID
+	End      StreamID
+	Count    int
+	Consumer string        // empty for every consumer
+	MinIdle  time.Duration // zero for no idle filter
+}
+
+type StreamClaimOptions struct {
+	Idle       *time.Duration // set the idle time of the claimed entries
+	Time       *time.Time     // set the delivery time of the claimed entries
+	RetryCount *int           // set the delivery count of the claimed entries
+	Force      bool           // create PEL entries for IDs that are not pending
+	JustID     bool           // do not increment the delivery count
+	LastID     *StreamID      // move the group last delivered ID forward
+}
+
+func newStreamGroup(name string, lastDeliveredID StreamID) *StreamGroup {
+	return &StreamGroup{
+		Name:            name,
+		LastDeliveredID: lastDeliveredID,
+		pending:         []*StreamPendingEntry{},
+		consumers:       make(map[string]*StreamConsumer),
+	}
+}
+
+// copy returns a group with its own pending entries and consumers
+func (group *StreamGroup) copy() *StreamGroup {
+	copied := newStreamGroup(group.Name, group.LastDeliveredID)
+
+	// consumers point at the same pending entries as the group
+	entries := make(map[*StreamPendingEntry]*StreamPendingEntry, len(group.pending))
+	for _, pe := range group.pending {
+		clone := *pe
+		entries[pe] = &clone
+		copied.pending = append(copied.pending, &clone)
+	}
+
+	for name, consumer := range group.consumers {
+		pending := make(map[StreamID]*StreamPendingEntry, len(consumer.pending))
+		for id, pe := range consumer.pending {
+			pending[id] = entries[pe]
+		}
+
+		copied.consumers[name] = &StreamConsumer{
+			Name:     consumer.Name,
+			SeenTime: consumer.SeenTime,
+			pending:  pending,
+		}
+	}
+
+	return copied
+}
+
+func (rs *RedisStream) CreateGroup(name string, lastDeliveredID StreamID) error {
+	if rs.groups == nil {
+		rs.groups = make(map[string]*StreamGroup)
+	}
+
+	if _, exists := rs.groups[name]; exists {
+		return errs.BusyGroup
+	}
+
+	rs.groups[name] = newStreamGroup(name, lastDeliveredID)
+	return nil
+}
+
+func (rs *RedisStream) Group(name string) (*StreamGroup, error) {
+	group, exists := rs.groups[name]
+	if !exists {
+		return nil, errs.NoGroup
+	}
+
+	return group, nil
+}
+
+func (rs *RedisStream) DestroyGroup(name string) bool {
+	if _, exists := rs.groups[name]; !exists {
+		return false
+	}
+
+	delete(rs.groups, name)
+	return true
+}
+
+// entry looks up a single entry by ID
+func (rs *RedisStream) entry(id StreamID) (StreamEntry, bool) {
+	i := rs.search(id)
+	if i >= len(rs.entries) || rs.entries[i].ID != id {
+		return StreamEntry{}, false
+	}
+
+	return rs.entries[i], true
+}
+
+// CreateConsumer returns false when the consumer already exists
+func (group *StreamGroup) CreateConsumer(name string) bool {
+	if _, exists := group.consumers[name]; exists {
+		return false
+	}
+
+	group.consumer(name)
+	return true
+}
+
+// DeleteConsumer drops the consumer along with its pending entries and returns how many it had
+func (group *StreamGroup) DeleteConsumer(name string) int {
+	consumer, exists := group.consumers[name]
+	if !exists {
+		return 0
+	}
+
+	count := len(consumer.pending)
+	for id := range consumer.pending {
+		group.removePending(id)
+	}
+
+	delete(group.consumers, name)
+	return count
+}
+
+func (group *StreamGroup) consumer(name string) *StreamConsumer {
+	consumer, exists := group.consumers[name]
+	if !exists {
+		consumer = &StreamConsumer{
+			Name:    name,
+			pending: make(map[StreamID]*StreamPendingEntry),
+		}
+		group.consumers[name] = consumer
+	}
+
+	consumer.SeenTime = time.Now()
+	return consumer
+}
+
+// searchPending returns the index of the first pending entry with an ID >= id
+func (group *StreamGroup) searchPending(id StreamID) int {
+	return sort.Search(len(group.pending), func(i int) bool {
+		return !group.pending[i].ID.Less(id)
+	})
+}
+
+func (group *StreamGroup) findPending(id StreamID) *StreamPendingEntry {
+	i := group.searchPending(id)
+	if i >= len(group.pending) || group.pending[i].ID != id {
+		return nil
+	}
+
+	return group.pending[i]
+}
+
+func (group *StreamGroup) insertPending(pe *StreamPendingEntry) {
+	i := group.searchPending(pe.ID)
+
+	// deliveries usually carry the highest ID, making this an append
+	group.pending = append(group.pending, nil)
+	copy(group.pending[i+1:], group.pending[i:])
+	group.pending[i] = pe
+}
+
+func (group *StreamGroup) removePending(id StreamID) bool {
+	i := group.searchPending(id)
+	if i >= len(group.pending) || group.pending[i].ID != id {
+		return false
+	}
+
+	pe := group.pending[i]
+	if consumer, exists := group.consumers[pe.Consumer]; exists {
+		delete(consumer.pending, id)
+	}
+
+	group.pending = append(group.pending[:i], group.pending[i+1:]...)
+	return true
+}
+
+// assign moves the pending entry to the consumer
+func (group *StreamGroup) assign(pe *StreamPendingEntry, consumer *StreamConsumer) {
+	if previous, exists := group.consumers[pe.Consumer]; exists {
+		delete(previous.pending, pe.ID)
+	}
+
+	pe.Consumer = consumer.Name
+	consumer.pending[pe.ID] = pe
+}
+
+// ReadNew delivers the entries after the last delivered ID to the consumer
+func (group *StreamGroup) ReadNew(rs *RedisStream, consumerName string, count int, noAck bool) []StreamEntry {
+	consumer := group.consumer(consumerName)
+
+	entries := rs.After(group.LastDeliveredID, count)
+	if len(entries) == 0 {
+		return entries
+	}
+
+	group.LastDeliveredID = entries[len(entries)-1].ID
+
+	if noAck {
+		return entries
+	}
+
+	now := time.Now()
+	for _, entry := range entries {
+		// an entry can only be pending once, a re-delivery after SETID takes it over
+		if pe := group.findPending(entry.ID); pe != nil {
+			group.assign(pe, consumer)
+			pe.DeliveryTime = now
+			pe.DeliveryCount = 1
+			continue
+		}
+
+		pe := &StreamPendingEntry{
+			ID:            entry.ID,
+			DeliveryTime:  now,
+			DeliveryCount: 1,
+		}
+		group.insertPending(pe)
+		group.assign(pe, consumer)
+	}
+
+	return entries
+}
+
+// ReadHistory re-delivers the consumer's own pending entries after the given ID.
+// Entries deleted from the stream come back with nil fields.
+func (group *StreamGroup) ReadHistory(rs *RedisStream, consumerName string, after StreamID, count int) []StreamEntry {
+	consumer := group.consumer(consumerName)
+	result := []StreamEntry{}
+
+	start, ok := after.Next()
+	if !ok {
+		return result
+	}
+
+	now := time.Now()
+	for i := group.searchPending(start); i < len(group.pending); i++ {
+		if count > 0 && len(result) >= count {
+			break
+		}
+
+		pe := group.pending[i]
+		if pe.Consumer != consumer.Name {
+			continue
+		}
+
+		pe.DeliveryTime = now
+		pe.DeliveryCount++
+
+		entry, exists := rs.entry(pe.ID)
+		if !exists {
+			entry = StreamEntry{ID: pe.ID}
+		}
+		result = append(result, entry)
+	}
+
+	return result
+}
+
+func (group *StreamGroup) Ack(ids []StreamID) int {
+	count := 0
+	for _, id := range ids {
+		if group.removePending(id) {
+			count++
+		}
+	}
+
+	return count
+}
+
+func (group *StreamGroup) PendingSummary() StreamPendingSummary {
+	summary := StreamPendingSummary{
+		Count:     len(group.pending),
+		Consumers: []StreamConsumerPending{},
+	}
+
+	if len(group.pending) == 0 {
+		return summary
+	}
+
+	summary.MinID = group.pending[0].ID
+	summary.MaxID = group.pending[len(group.pending)-1].ID
+
+	for _, consumer := range group.consumers {
+		if len(consumer.pending) > 0 {
+			summary.Consumers = append(summary.Consumers, StreamConsumerPending{
+				Consumer: consumer.Name,
+				Count:    len(consumer.pending),
+			})
+		}
+	}
+
+	sort.Slice(summary.Consumers, func(i, j int) bool {
+		return summary.Consumers[i].Consumer < summary.Consumers[j].Consumer
+	})
+
+	return summary
+}
+
+func (group *StreamGroup) PendingRange(query StreamPendingQuery) []StreamPendingEntry {
+	result := []StreamPendingEntry{}
+	now := time.Now()
+
+	for i := group.searchPending(query.Start); i < len(group.pending) && len(result) < query.Count; i++ {
+		pe := group.pending[i]
+		if query.End.Less(pe.ID) {
+			break
+		}
+
+		if query.Consumer != "" && pe.Consumer != query.Consumer {
+			continue
+		}
+
+		if pe.Idle(now) < query.MinIdle {
+			continue
+		}
+
+		result = append(result, *pe)
+	}
+
+	return result
+}
+
+// Claim transfers the pending entries idle for at least minIdle to the consumer.
+// Pending entries whose stream entry was deleted are dropped instead and returned apart.
+func (group *StreamGroup) Claim(rs *RedisStream, consumerName string, minIdle time.Duration, ids []StreamID, options StreamClaimOptions) ([]StreamEntry, []StreamID) {
+	consumer := group.consumer(consumerName)
+	claimed := []StreamEntry{}
+	deleted := []StreamID{}
+	now := time.Now()
+
+	if options.LastID != nil && group.LastDeliveredID.Less(*options.LastID) {
+		group.LastDeliveredID = *options.LastID
+	}
+
+	deliveryTime := now
+	if options.Idle != nil {
+		deliveryTime = now.Add(-*options.Idle)
+	} else if options.Time != nil {
+		deliveryTime = *options.Time
+	}
+
+	for _, id := range ids {
+		entry, exists := rs.entry(id)
+		pe := group.findPending(id)
+
+		if pe == nil {
+			if !options.Force || !exists {
+				continue
+			}
+
+			pe = &StreamPendingEntry{ID: id}
+			group.insertPending(pe)
+		} else if !exists {
+			group.removePending(id)
+			deleted = append(deleted, id)
+			continue
+		} else if minIdle > 0 && pe.Idle(now) < minIdle {
+			continue
+		}
+
+		group.assign(pe, consumer)
+		pe.DeliveryTime = deliveryTime
+
+		if options.RetryCount != nil {
+			pe.DeliveryCount = *options.RetryCount
+		} else if !options.JustID {
+			pe.DeliveryCount++
+		}
+
+		claimed = append(claimed, entry)
+	}
+
+	return claimed, deleted
+}
+
+// AutoClaim scans the PEL from start, claiming up to count entries idle for at least minIdle.
+// It returns the ID to resume the scan from (0-0 once the PEL is exhausted), the claimed
+// entries and the IDs that were dropped because they no longer exist in the stream.
+func (group *StreamGroup) AutoClaim(rs *RedisStream, consumerName string, minIdle time.Duration, start StreamID, count int, justID bool) (StreamID, []StreamEntry, []StreamID) {
+	consumer := group.consumer(consumerName)
+	claimed := []StreamEntry{}
+	deleted := []StreamID{}
+	now := time.Now()
+
+	// bound the work done per call like redis does
+	attempts := count * 10
+
+	i := group.searchPending(start)
+	for i < len(group.pending) && len(claimed) < count && attempts > 0 {
+		attempts--
+		pe := group.pending[i]
+
+		entry, exists := rs.entry(pe.ID)
+		if !exists {
+			deleted = append(deleted, pe.ID)
+			group.removePending(pe.ID)
+			continue
+		}
+
+		i++
+		if minIdle > 0 && pe.Idle(now) < minIdle {
+			continue
+		}
+
+		group.assign(pe, consumer)
+		pe.DeliveryTime = now
+		if !justID {
+			pe.DeliveryCount++
+		}
+
+		claimed = append(claimed, entry)
+	}
+
+	next := MinStreamID
+	if i < len(group.pending) {
+		next = group.pending[i].ID
+	}
+
+	return next, claimed, deleted
+}
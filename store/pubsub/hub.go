@@ -0,0 +1,332 @@
+package pubsub
+
+import (
+	"maps"
+	"server/commands/serializer"
+	"server/glob"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// MODEL: PUBLISHERS PUSH SERIALIZED FRAMES INTO A BUFFERED CHANNEL PER SUBSCRIBER,
+// THE CONNECTION OF THE SUBSCRIBER WRITES THEM OUT
+
+// frames a subscriber may lag behind before it is disconnected
+var MESSAGE_BUFFER = 1024
+
+// subscriptions maps a channel or pattern to its subscribers
+type subscriptions map[string]map[*Subscriber]struct{}
+
+// Hub routes published messages to the connections subscribed to their channel,
+// or to a pattern matching it. Shard channels are a namespace of their own.
+type Hub struct {
+	mu           sync.RWMutex
+	channels     subscriptions
+	patterns     subscriptions
+	patternIndex *patternIndex
+
+	// every slot is served by this node, so shard channels need no per slot routing
+	shardChannels subscriptions
+
+	sr *serializer.Serializer
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		channels:     make(subscriptions),
+		patterns:     make(subscriptions),
+		patternIndex: newPatternIndex(),
+
+		shardChannels: make(subscriptions),
+
+		sr: serializer.NewSerializer(),
+	}
+}
+
+// Subscriber is the pub/sub state of a connection
+type Subscriber struct {
+	messages chan []byte
+
+	// closed when the buffer is full, the connection is dropped
+	overflow     chan struct{}
+	overflowOnce sync.Once
+
+	// guarded by the hub lock
+	channels      map[string]struct{}
+	patterns      map[string]struct{}
+	shardChannels map[string]struct{}
+
+	count atomic.Int64 // subscriptions of every kind
+}
+
+func NewSubscriber() *Subscriber {
+	return &Subscriber{
+		messages:      make(chan []byte, MESSAGE_BUFFER),
+		overflow:      make(chan struct{}),
+		channels:      make(map[string]struct{}),
+		patterns:      make(map[string]struct{}),
+		shardChannels: make(map[string]struct{}),
+	}
+}
+
+// Messages delivers the frames to write to the connection
+func (sub *Subscriber) Messages() <-chan []byte {
+	return sub.messages
+}
+
+// Overflow is closed once the connection fell too far behind its messages
+func (sub *Subscriber) Overflow() <-chan struct{} {
+	return sub.overflow
+}
+
+// Subscribed reports whether the connection is in subscribed mode
+func (sub *Subscriber) Subscribed() bool {
+	return sub.count.Load() > 0
+}
+
+func (sub *Subscriber) deliver(frame []byte) {
+	select {
+	case sub.messages <- frame:
+	default:
+		sub.overflowOnce.Do(func() { close(sub.overflow) })
+	}
+}
+
+func (sub *Subscriber) updateCount() {
+	// must be used with the hub lock
+	sub.count.Store(int64(len(sub.channels) + len(sub.patterns) + len(sub.shardChannels)))
+}
+
+// classicCount is the count (P)SUBSCRIBE replies with, shard channels are counted apart
+func (sub *Subscriber) classicCount() int {
+	// must be used with the hub lock
+	return len(sub.channels) + len(sub.patterns)
+}
+
+func (sub *Subscriber) shardCount() int {
+	// must be used with the hub lock
+	return len(sub.shardChannels)
+}
+
+// subscribe adds the names to the subscriptions of the subscriber, returning the count after
+// each of them. added is called with the names that had no subscribers yet.
+func (hub *Hub) subscribe(sub *Subscriber, owned map[string]struct{}, routes subscriptions, names []string, added func(string), count func() int) []int {
+	// must be used with the hub lock
+	counts := make([]int, len(names))
+
+	for i, name := range names {
+		if _, subscribed := owned[name]; !subscribed {
+			owned[name] = struct{}{}
+
+			if routes[name] == nil {
+				routes[name] = make(map[*Subscriber]struct{})
+				added(name)
+			}
+			routes[name][sub] = struct{}{}
+		}
+
+		sub.updateCount()
+		counts[i] = count()
+	}
+
+	return counts
+}
+
+// unsubscribe removes the names, every one the subscriber has when none are given. It returns
+// the names removed and the count after each of them. removed is called with the names
+// left without subscribers.
+func (hub *Hub) unsubscribe(sub *Subscriber, owned map[string]struct{}, routes subscriptions, names []string, removed func(string), count func() int) ([]string, []int) {
+	// must be used with the hub lock
+	if len(names) == 0 {
+		names = slices.Sorted(maps.Keys(owned))
+	}
+
+	counts := make([]int, len(names))
+
+	for i, name := range names {
+		delete(owned, name)
+
+		if _, exists := routes[name][sub]; exists {
+			delete(routes[name], sub)
+			if len(routes[name]) == 0 {
+				delete(routes, name)
+				removed(name)
+			}
+		}
+
+		sub.updateCount()
+		counts[i] = count()
+	}
+
+	return names, counts
+}
+
+// Subscribe adds the channels and returns the subscription count after each of them
+func (hub *Hub) Subscribe(sub *Subscriber, channels []string) []int {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	return hub.subscribe(sub, sub.channels, hub.channels, channels, func(string) {}, sub.classicCount)
+}
+
+// Unsubscribe removes the channels, every channel of the subscriber when none are given.
+// It returns the channels removed and the subscription count after each of them.
+func (hub *Hub) Unsubscribe(sub *Subscriber, channels []string) ([]string, []int) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	return hub.unsubscribe(sub, sub.channels, hub.channels, channels, func(string) {}, sub.classicCount)
+}
+
+// PSubscribe adds the glob patterns, see Subscribe
+func (hub *Hub) PSubscribe(sub *Subscriber, patterns []string) []int {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	return hub.subscribe(sub, sub.patterns, hub.patterns, patterns, hub.patternIndex.add, sub.classicCount)
+}
+
+// PUnsubscribe removes the glob patterns, see Unsubscribe
+func (hub *Hub) PUnsubscribe(sub *Subscriber, patterns []string) ([]string, []int) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	return hub.unsubscribe(sub, sub.patterns, hub.patterns, patterns, hub.patternIndex.remove, sub.classicCount)
+}
+
+// SSubscribe adds the shard channels and returns the shard subscription count after each of them
+func (hub *Hub) SSubscribe(sub *Subscriber, channels []string) []int {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	return hub.subscribe(sub, sub.shardChannels, hub.shardChannels, channels, func(string) {}, sub.shardCount)
+}
+
+// SUnsubscribe removes the shard channels, see Unsubscribe
+func (hub *Hub) SUnsubscribe(sub *Subscriber, channels []string) ([]string, []int) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	return hub.unsubscribe(sub, sub.shardChannels, hub.shardChannels, channels, func(string) {}, sub.shardCount)
+}
+
+// UnsubscribeAll drops every subscription of a closed connection
+func (hub *Hub) UnsubscribeAll(sub *Subscriber) {
+	hub.Unsubscribe(sub, nil)
+	hub.PUnsubscribe(sub, nil)
+	hub.SUnsubscribe(sub, nil)
+}
+
+// Publish sends the message to the subscribers of the channel and of every pattern matching it,
+// returning how many frames were delivered. Like redis a connection subscribed to the channel
+// and to matching patterns gets one message plus one pmessage per pattern, a pattern
+// subscribed to twice is a single subscription.
+// Publishing is exclusive so that every subscriber sees the messages in the same order.
+func (hub *Hub) Publish(channel, message string) int {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	delivered := 0
+
+	if subscribers := hub.channels[channel]; len(subscribers) > 0 {
+		frame := hub.sr.GetMessageBytes(channel, message)
+		for sub := range subscribers {
+			sub.deliver(frame)
+		}
+		delivered += len(subscribers)
+	}
+
+	hub.patternIndex.match(channel, func(pattern string) {
+		subscribers := hub.patterns[pattern]
+
+		frame := hub.sr.GetPatternMessageBytes(pattern, channel, message)
+		for sub := range subscribers {
+			sub.deliver(frame)
+		}
+		delivered += len(subscribers)
+	})
+
+	return delivered
+}
+
+// SPublish sends the message to the subscribers of the shard channel only,
+// returning how many received it
+func (hub *Hub) SPublish(channel, message string) int {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	subscribers := hub.shardChannels[channel]
+	if len(subscribers) == 0 {
+		return 0
+	}
+
+	frame := hub.sr.GetShardMessageBytes(channel, message)
+	for sub := range subscribers {
+		sub.deliver(frame)
+	}
+
+	return len(subscribers)
+}
+
+// Channels returns the channels with subscribers matching the glob pattern, every one when it is empty
+func (hub *Hub) Channels(pattern string) []string {
+	hub.mu.RLock()
+	defer hub.mu.RUnlock()
+
+	return hub.channels.names(pattern)
+}
+
+// ShardChannels returns the shard channels with subscribers matching the glob pattern, see Channels
+func (hub *Hub) ShardChannels(pattern string) []string {
+	hub.mu.RLock()
+	defer hub.mu.RUnlock()
+
+	return hub.shardChannels.names(pattern)
+}
+
+// NumPat returns how many distinct patterns are subscribed to
+func (hub *Hub) NumPat() int {
+	hub.mu.RLock()
+	defer hub.mu.RUnlock()
+
+	return len(hub.patterns)
+}
+
+// NumSub returns the subscriber count of each channel
+func (hub *Hub) NumSub(channels []string) []int {
+	hub.mu.RLock()
+	defer hub.mu.RUnlock()
+
+	return hub.channels.counts(channels)
+}
+
+// ShardNumSub returns the subscriber count of each shard channel
+func (hub *Hub) ShardNumSub(channels []string) []int {
+	hub.mu.RLock()
+	defer hub.mu.RUnlock()
+
+	return hub.shardChannels.counts(channels)
+}
+
+func (routes subscriptions) names(pattern string) []string {
+	// must be used with the hub lock
+	names := []string{}
+	for name := range routes {
+		if pattern == "" || glob.Match(pattern, name) {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+func (routes subscriptions) counts(names []string) []int {
+	// must be used with the hub lock
+	counts := make([]int, len(names))
+	for i, name := range names {
+		counts[i] = len(routes[name])
+	}
+
+	return counts
+}
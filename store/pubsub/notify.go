@@ -0,0 +1,129 @@
+package pubsub
+
+import (
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// ———————————————————————————————————————————————————————————————
+// Keyspace notifications
+// ———————————————————————————————————————————————————————————————
+
+// EventClass is a set of the notify-keyspace-events classes
+type EventClass int
+
+const (
+	NotifyKeyspace EventClass = 1 << iota // K, published to __keyspace@<db>__:<key>
+	NotifyKeyevent                        // E, published to __keyevent@<db>__:<event>
+	NotifyGeneric                         // g, commands like DEL, EXPIRE and RENAME
+	NotifyString                          // $
+	NotifyList                            // l
+	NotifySet                             // s
+	NotifyHash                            // h
+	NotifyZSet                            // z
+	NotifyExpired                         // x, keys and hash fields reaching their TTL
+	NotifyEvicted                         // e, nothing is evicted so it is never published
+	NotifyStream                          // t
+
+	// A, every class but K and E
+	NotifyAll = NotifyGeneric | NotifyString | NotifyList | NotifySet | NotifyHash | NotifyZSet |
+		NotifyExpired | NotifyEvicted | NotifyStream
+)
+
+var eventClassFlags = []struct {
+	flag  byte
+	class EventClass
+}{
+	{'g', NotifyGeneric},
+	{'$', NotifyString},
+	{'l', NotifyList},
+	{'s', NotifySet},
+	{'h', NotifyHash},
+	{'z', NotifyZSet},
+	{'x', NotifyExpired},
+	{'e', NotifyEvicted},
+	{'t', NotifyStream},
+	{'K', NotifyKeyspace},
+	{'E', NotifyKeyevent},
+}
+
+// ParseEventClasses reads a notify-keyspace-events string like "KEA" or "Ex",
+// the bool is false when it holds an unknown class
+func ParseEventClasses(s string) (EventClass, bool) {
+	classes := EventClass(0)
+
+outer:
+	for i := 0; i < len(s); i++ {
+		if s[i] == 'A' {
+			classes |= NotifyAll
+			continue
+		}
+
+		for _, flag := range eventClassFlags {
+			if flag.flag == s[i] {
+				classes |= flag.class
+				continue outer
+			}
+		}
+
+		return 0, false
+	}
+
+	return classes, true
+}
+
+// String renders the classes the way CONFIG GET shows them, A standing for every type class
+func (classes EventClass) String() string {
+	var sb strings.Builder
+
+	for _, flag := range eventClassFlags {
+		if flag.class&NotifyAll != 0 && classes&NotifyAll == NotifyAll {
+			continue
+		}
+
+		if classes&flag.class != 0 {
+			sb.WriteByte(flag.flag)
+		}
+	}
+
+	if classes&NotifyAll == NotifyAll {
+		return "A" + sb.String()
+	}
+
+	return sb.String()
+}
+
+// Notifier publishes the keyspace notifications of the enabled classes, none are by default
+type Notifier struct {
+	hub     *Hub
+	classes atomic.Int64
+}
+
+func NewNotifier(hub *Hub) *Notifier {
+	return &Notifier{hub: hub}
+}
+
+func (notifier *Notifier) Classes() EventClass {
+	return EventClass(notifier.classes.Load())
+}
+
+func (notifier *Notifier) SetClasses(classes EventClass) {
+	notifier.classes.Store(int64(classes))
+}
+
+// Notify publishes that the event of the class happened to the key of database db
+func (notifier *Notifier) Notify(class EventClass, event, key string, db int) {
+	classes := notifier.Classes()
+	if classes&class == 0 {
+		return
+	}
+
+	if classes&NotifyKeyspace != 0 {
+		notifier.hub.Publish("__keyspace@"+strconv.Itoa(db)+"__:"+key, event)
+	}
+
+	if classes&NotifyKeyevent != 0 {
+		notifier.hub.Publish("__keyevent@"+strconv.Itoa(db)+"__:"+event, key)
+	}
+}
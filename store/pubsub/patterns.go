@@ -0,0 +1,110 @@
+package pubsub
+
+import "server/glob"
+
+// ———————————————————————————————————————————————————————————————
+// Pattern index
+// ———————————————————————————————————————————————————————————————
+
+// patternIndex finds the patterns matching a channel without trying every one of them.
+// A pattern is stored in a trie under its literal prefix, the bytes before its first
+// special character, so only the patterns whose prefix starts the channel are matched.
+type patternIndex struct {
+	root *prefixNode
+}
+
+type prefixNode struct {
+	children map[byte]*prefixNode
+	patterns map[string]struct{}
+}
+
+func newPatternIndex() *patternIndex {
+	return &patternIndex{root: newPrefixNode()}
+}
+
+func newPrefixNode() *prefixNode {
+	return &prefixNode{
+		children: make(map[byte]*prefixNode),
+		patterns: make(map[string]struct{}),
+	}
+}
+
+// literalPrefix returns the part of the pattern every matching channel starts with
+func literalPrefix(pattern string) string {
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '*', '?', '[', '\\':
+			return pattern[:i]
+		}
+	}
+
+	return pattern
+}
+
+func (index *patternIndex) add(pattern string) {
+	node := index.root
+	prefix := literalPrefix(pattern)
+
+	for i := 0; i < len(prefix); i++ {
+		child, exists := node.children[prefix[i]]
+		if !exists {
+			child = newPrefixNode()
+			node.children[prefix[i]] = child
+		}
+		node = child
+	}
+
+	node.patterns[pattern] = struct{}{}
+}
+
+// remove drops the pattern along with the trie nodes left empty
+func (index *patternIndex) remove(pattern string) {
+	prefix := literalPrefix(pattern)
+
+	path := make([]*prefixNode, 0, len(prefix)+1)
+	node := index.root
+
+	for i := 0; i < len(prefix); i++ {
+		path = append(path, node)
+
+		child, exists := node.children[prefix[i]]
+		if !exists {
+			return
+		}
+		node = child
+	}
+
+	delete(node.patterns, pattern)
+
+	for i := len(prefix) - 1; i >= 0; i-- {
+		if len(node.patterns) > 0 || len(node.children) > 0 {
+			return
+		}
+
+		node = path[i]
+		delete(node.children, prefix[i])
+	}
+}
+
+// match calls fn with every pattern matching the channel
+func (index *patternIndex) match(channel string, fn func(pattern string)) {
+	node := index.root
+
+	for i := 0; ; i++ {
+		for pattern := range node.patterns {
+			if glob.Match(pattern, channel) {
+				fn(pattern)
+			}
+		}
+
+		if i == len(channel) {
+			return
+		}
+
+		child, exists := node.children[channel[i]]
+		if !exists {
+			return
+		}
+		node = child
+	}
+}
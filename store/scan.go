@@ -0,0 +1,127 @@
+package store
+
+import (
+	"server/errs"
+	"server/glob"
+	"server/store/actions"
+	"server/store/objects"
+)
+
+// ———————————————————————————————————————————————————————————————
+// Cursor based iteration
+// ———————————————————————————————————————————————————————————————
+
+// The keyspace and the hashes, sets and sorted sets keep their names in an objects.Dict,
+// a cursor is the bucket to continue from. See Dict.Scan for what an iteration guarantees.
+
+type ScanOptions struct {
+	Match    string           // glob the names have to match, empty matches everything
+	Count    int              // how many names to look at, the reply may be smaller after filtering
+	DataType objects.DataType // only keys of this type, SCAN only
+}
+
+func matches(pattern, name string) bool {
+	return pattern == "" || glob.Match(pattern, name)
+}
+
+func (store *Store) Scan(cursor uint64, options ScanOptions) ([]string, uint64) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	batch, next := store.kvMap.Scan(cursor, options.Count)
+
+	keys := []string{}
+	for _, key := range batch {
+		object, exists := store.getObject(key)
+		if !exists {
+			continue
+		}
+
+		if options.DataType != "" && object.DataType != options.DataType {
+			continue
+		}
+
+		if matches(options.Match, key) {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys, next
+}
+
+// HScan returns the matching fields of the batch, with their values unless noValues is set
+func (store *Store) HScan(key string, cursor uint64, options ScanOptions, noValues bool) ([]string, uint64, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	hs, err := store.getHash(key, actions.HScan)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	batch, next := hs.Scan(cursor, options.Count)
+
+	items := []string{}
+	for _, field := range batch {
+		if !matches(options.Match, field) {
+			continue
+		}
+
+		items = append(items, field)
+		if !noValues {
+			value, _ := hs.Get(field)
+			items = append(items, value)
+		}
+	}
+
+	return items, next, nil
+}
+
+func (store *Store) SScan(key string, cursor uint64, options ScanOptions) ([]string, uint64, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	rs, err := store.getSet(key, actions.SScan)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	batch, next := rs.Scan(cursor, options.Count)
+
+	members := []string{}
+	for _, member := range batch {
+		if matches(options.Match, member) {
+			members = append(members, member)
+		}
+	}
+
+	return members, next, nil
+}
+
+func (store *Store) ZScan(key string, cursor uint64, options ScanOptions) ([]objects.ScoredMember, uint64, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	zs, err := store.getSortedSet(key, actions.ZScan)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if zs.Len() == 0 {
+		return nil, 0, errs.ErrNotFound
+	}
+
+	batch, next := zs.Scan(cursor, options.Count)
+
+	scored := []objects.ScoredMember{}
+	for _, member := range batch {
+		if !matches(options.Match, member) {
+			continue
+		}
+
+		score, _ := zs.Score(member)
+		scored = append(scored, objects.ScoredMember{Member: member, Score: score})
+	}
+
+	return scored, next, nil
+}
@@ -0,0 +1,201 @@
+package store
+
+import (
+	"server/errs"
+	"server/store/actions"
+	"server/store/objects"
+	"server/store/pubsub"
+)
+
+// ———————————————————————————————————————————————————————————————
+// Set methods
+// ———————————————————————————————————————————————————————————————
+
+func (store *Store) getSet(key string, action actions.Action) (objects.RedisSet, error) {
+	// must be used with a lock
+	object, exists := store.getObject(key)
+	if !exists {
+		return objects.RedisSet{}, errs.ErrNotFound
+	}
+
+	object, err := store.validateActionForDataType(object, action)
+	if err != nil {
+		return objects.RedisSet{}, err
+	}
+
+	return objects.ValidateObjectAsSet(object)
+}
+
+func (store *Store) SAdd(key string, members []string) (int, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	object, exists := store.getObject(key)
+
+	if !exists {
+		object = objects.NewObject(objects.Set, objects.NewRedisSet())
+		store.kvMap.Set(key, object)
+	}
+
+	object, err := store.validateActionForDataType(object, actions.SAdd)
+	if err != nil {
+		return 0, err
+	}
+
+	rs, err := objects.ValidateObjectAsSet(object)
+	if err != nil {
+		return 0, err
+	}
+
+	added := rs.Add(members)
+	if added > 0 {
+		store.keyModified(key)
+		store.notify(pubsub.NotifySet, "sadd", key)
+	}
+
+	return added, nil
+}
+
+func (store *Store) SRem(key string, members []string) (int, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	rs, err := store.getSet(key, actions.SRem)
+	if err != nil {
+		return 0, err
+	}
+
+	count := rs.Remove(members)
+	if count > 0 {
+		store.keyModified(key)
+		store.notifyRemoval(pubsub.NotifySet, "srem", key, rs.Size() == 0)
+	}
+
+	if rs.Size() == 0 {
+		store.kvMap.Delete(key)
+	}
+
+	return count, nil
+}
+
+func (store *Store) SMembers(key string) ([]string, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	rs, err := store.getSet(key, actions.SMembers)
+	if err != nil {
+		return nil, err
+	}
+
+	return rs.Members(), nil
+}
+
+func (store *Store) SIsMember(key, member string) (bool, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	rs, err := store.getSet(key, actions.SIsMember)
+	if err != nil {
+		return false, err
+	}
+
+	return rs.Contains(member), nil
+}
+
+func (store *Store) SCard(key string) (int, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	rs, err := store.getSet(key, actions.SCard)
+	if err != nil {
+		return 0, err
+	}
+
+	return rs.Size(), nil
+}
+
+type setAlgebraFn func(sets []objects.RedisSet) objects.RedisSet
+
+func (store *Store) collectSets(keys []string, action actions.Action) ([]objects.RedisSet, error) {
+	// must be used with a lock
+	// missing keys behave like empty sets
+	sets := make([]objects.RedisSet, len(keys))
+
+	for i, key := range keys {
+		rs, err := store.getSet(key, action)
+		if err == errs.ErrNotFound {
+			sets[i] = objects.NewRedisSet()
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		sets[i] = rs
+	}
+
+	return sets, nil
+}
+
+func (store *Store) setAlgebra(keys []string, action actions.Action, algebraFn setAlgebraFn) ([]string, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	sets, err := store.collectSets(keys, action)
+	if err != nil {
+		return nil, err
+	}
+
+	return algebraFn(sets).Members(), nil
+}
+
+func (store *Store) setAlgebraStore(destination string, keys []string, action actions.Action, algebraFn setAlgebraFn) (int, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	sets, err := store.collectSets(keys, action)
+	if err != nil {
+		return 0, err
+	}
+
+	result := algebraFn(sets)
+	store.keyModified(destination)
+
+	// the destination is overwritten whatever its type, an empty result removes it
+	if result.Size() == 0 {
+		if _, exists := store.getObject(destination); exists {
+			store.notify(pubsub.NotifyGeneric, "del", destination)
+		}
+		store.kvMap.Delete(destination)
+		return 0, nil
+	}
+
+	store.kvMap.Set(destination, objects.NewObject(objects.Set, result))
+	store.notify(pubsub.NotifySet, string(action), destination)
+	return result.Size(), nil
+}
+
+func (store *Store) SInter(keys []string) ([]string, error) {
+	return store.setAlgebra(keys, actions.SInter, objects.SetIntersection)
+}
+
+func (store *Store) SUnion(keys []string) ([]string, error) {
+	return store.setAlgebra(keys, actions.SUnion, objects.SetUnion)
+}
+
+func (store *Store) SDiff(keys []string) ([]string, error) {
+	return store.setAlgebra(keys, actions.SDiff, objects.SetDifference)
+}
+
+func (store *Store) SInterStore(destination string, keys []string) (int, error) {
+	return store.setAlgebraStore(destination, keys, actions.SInterStore, objects.SetIntersection)
+}
+
+func (store *Store) SUnionStore(destination string, keys []string) (int, error) {
+	return store.setAlgebraStore(destination, keys, actions.SUnionStore, objects.SetUnion)
+}
+
+func (store *Store) SDiffStore(destination string, keys []string) (int, error) {
+	return store.setAlgebraStore(destination, keys, actions.SDiffStore, objects.SetDifference)
+}
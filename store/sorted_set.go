@@ -0,0 +1,598 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"math"
+	"server/errs"
+	"server/store/actions"
+	"server/store/objects"
+	"server/store/pubsub"
+	"strconv"
+	"time"
+)
+
+// ———————————————————————————————————————————————————————————————
+// Sorted set methods
+// ———————————————————————————————————————————————————————————————
+
+type ZAddOptions struct {
+	NX   bool // only add new members
+	XX   bool // only update existing members
+	GT   bool // only update when the new score is greater
+	LT   bool // only update when the new score is lower
+	CH   bool // count changed members as well as added ones
+	Incr bool // increment the score instead of setting it
+}
+
+func (store *Store) getSortedSet(key string, action actions.Action) (*objects.SortedSet, error) {
+	// must be used with a lock
+	object, exists := store.getObject(key)
+	if !exists {
+		return nil, errs.ErrNotFound
+	}
+
+	object, err := store.validateActionForDataType(object, action)
+	if err != nil {
+		return nil, err
+	}
+
+	return objects.ValidateObjectAsSortedSet(object)
+}
+
+func (store *Store) deleteSortedSetIfEmpty(key string, zs *objects.SortedSet) {
+	// must be used with a lock
+	if zs.Len() == 0 {
+		store.kvMap.Delete(key)
+	}
+}
+
+func (store *Store) getOrCreateSortedSet(key string, action actions.Action) (*objects.SortedSet, error) {
+	// must be used with a lock
+	zs, err := store.getSortedSet(key, action)
+	if err == errs.ErrNotFound {
+		zs = objects.NewSortedSet()
+		store.kvMap.Set(key, objects.NewObject(objects.ZSet, zs))
+		return zs, nil
+	}
+
+	return zs, err
+}
+
+type zaddResult struct {
+	score   float64
+	added   bool
+	changed bool
+	applied bool // false when the options prevented the update
+}
+
+func zaddMember(zs *objects.SortedSet, member string, score float64, options ZAddOptions) (zaddResult, error) {
+	current, exists := zs.Score(member)
+
+	if !exists {
+		if options.XX {
+			return zaddResult{}, nil
+		}
+
+		zs.Add(member, score)
+		return zaddResult{score: score, added: true, changed: true, applied: true}, nil
+	}
+
+	if options.NX {
+		return zaddResult{score: current}, nil
+	}
+
+	if options.Incr {
+		score += current
+		if math.IsNaN(score) {
+			return zaddResult{}, errs.ScoreIsNaN
+		}
+	}
+
+	if (options.GT && score <= current) || (options.LT && score >= current) {
+		return zaddResult{score: current}, nil
+	}
+
+	zs.Add(member, score)
+	return zaddResult{score: score, changed: score != current, applied: true}, nil
+}
+
+func (store *Store) ZAdd(key string, options ZAddOptions, members []objects.ScoredMember) (int, error) {
+	count, dispersals, err := store.zadd(key, options, members)
+	store.disperseToZSetClients(dispersals)
+
+	return count, err
+}
+
+func (store *Store) zadd(key string, options ZAddOptions, members []objects.ScoredMember) (int, []objects.ZSetBlockingPopDispersal, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var zs *objects.SortedSet
+	var err error
+
+	if options.XX {
+		zs, err = store.getSortedSet(key, actions.ZAdd)
+		if err == errs.ErrNotFound {
+			return 0, nil, nil
+		}
+	} else {
+		zs, err = store.getOrCreateSortedSet(key, actions.ZAdd)
+	}
+
+	if err != nil {
+		return 0, nil, err
+	}
+
+	count := 0
+	changed := false
+	for _, m := range members {
+		result, err := zaddMember(zs, m.Member, m.Score, options)
+		if err != nil {
+			return count, store.serveZSetClients(key, zs), err
+		}
+
+		if result.changed {
+			store.keyModified(key)
+			changed = true
+		}
+
+		if result.added || (options.CH && result.changed) {
+			count++
+		}
+	}
+
+	if changed {
+		store.notify(pubsub.NotifyZSet, "zadd", key)
+	}
+
+	return count, store.serveZSetClients(key, zs), nil
+}
+
+// ZAddIncr increments a single member, the returned bool is false when the options
+// prevented the update
+func (store *Store) ZAddIncr(key string, options ZAddOptions, member string, increment float64) (float64, bool, error) {
+	score, applied, dispersals, err := store.zaddIncr(key, options, member, increment)
+	store.disperseToZSetClients(dispersals)
+
+	return score, applied, err
+}
+
+func (store *Store) zaddIncr(key string, options ZAddOptions, member string, increment float64) (float64, bool, []objects.ZSetBlockingPopDispersal, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	options.Incr = true
+
+	var zs *objects.SortedSet
+	var err error
+
+	if options.XX {
+		zs, err = store.getSortedSet(key, actions.ZAdd)
+		if err == errs.ErrNotFound {
+			return 0, false, nil, nil
+		}
+	} else {
+		zs, err = store.getOrCreateSortedSet(key, actions.ZAdd)
+	}
+
+	if err != nil {
+		return 0, false, nil, err
+	}
+
+	result, err := zaddMember(zs, member, increment, options)
+	if err != nil {
+		store.deleteSortedSetIfEmpty(key, zs)
+		return 0, false, nil, err
+	}
+
+	if result.changed {
+		store.keyModified(key)
+		store.notify(pubsub.NotifyZSet, "zincr", key)
+	}
+
+	return result.score, result.applied, store.serveZSetClients(key, zs), nil
+}
+
+func (store *Store) ZIncrBy(key string, increment float64, member string) (float64, error) {
+	score, _, err := store.ZAddIncr(key, ZAddOptions{}, member, increment)
+	return score, err
+}
+
+func (store *Store) ZRem(key string, members []string) (int, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	zs, err := store.getSortedSet(key, actions.ZRem)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, member := range members {
+		if zs.Remove(member) {
+			count++
+		}
+	}
+
+	if count > 0 {
+		store.keyModified(key)
+		store.notifyRemoval(pubsub.NotifyZSet, "zrem", key, zs.Len() == 0)
+	}
+
+	store.deleteSortedSetIfEmpty(key, zs)
+
+	return count, nil
+}
+
+func (store *Store) ZScore(key, member string) (float64, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	zs, err := store.getSortedSet(key, actions.ZScore)
+	if err != nil {
+		return 0, err
+	}
+
+	score, exists := zs.Score(member)
+	if !exists {
+		return 0, errs.ErrNotFound
+	}
+
+	return score, nil
+}
+
+func (store *Store) ZRank(key, member string, reverse bool) (int, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	action := actions.ZRank
+	if reverse {
+		action = actions.ZRevRank
+	}
+
+	zs, err := store.getSortedSet(key, action)
+	if err != nil {
+		return 0, err
+	}
+
+	rank, exists := zs.Rank(member, reverse)
+	if !exists {
+		return 0, errs.ErrNotFound
+	}
+
+	return rank, nil
+}
+
+func (store *Store) ZCard(key string) (int, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	zs, err := store.getSortedSet(key, actions.ZCard)
+	if err != nil {
+		return 0, err
+	}
+
+	return zs.Len(), nil
+}
+
+func (store *Store) ZRangeByRank(key string, start, stop int, reverse bool) ([]objects.ScoredMember, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	zs, err := store.getSortedSet(key, actions.ZRange)
+	if err != nil {
+		return nil, err
+	}
+
+	return zs.RangeByRank(start, stop, reverse), nil
+}
+
+func (store *Store) ZRangeByScore(key string, r objects.ScoreRange, reverse bool, offset, count int) ([]objects.ScoredMember, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	zs, err := store.getSortedSet(key, actions.ZRange)
+	if err != nil {
+		return nil, err
+	}
+
+	return zs.RangeByScore(r, reverse, offset, count), nil
+}
+
+func (store *Store) ZRangeByLex(key string, r objects.LexRange, reverse bool, offset, count int) ([]objects.ScoredMember, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	zs, err := store.getSortedSet(key, actions.ZRange)
+	if err != nil {
+		return nil, err
+	}
+
+	return zs.RangeByLex(r, reverse, offset, count), nil
+}
+
+func (store *Store) ZPop(key string, count int, max bool) ([]objects.ScoredMember, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	action := actions.ZPopMin
+	if max {
+		action = actions.ZPopMax
+	}
+
+	zs, err := store.getSortedSet(key, action)
+	if err != nil {
+		return nil, err
+	}
+
+	popped := zs.Pop(count, max)
+	if len(popped) > 0 {
+		store.keyModified(key)
+		store.notifyRemoval(pubsub.NotifyZSet, string(action), key, zs.Len() == 0)
+	}
+	store.deleteSortedSetIfEmpty(key, zs)
+
+	return popped, nil
+}
+
+type zsetRemoveFn func(zs *objects.SortedSet) int
+
+func (store *Store) zremRange(key string, action actions.Action, removeFn zsetRemoveFn) (int, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	zs, err := store.getSortedSet(key, action)
+	if err != nil {
+		return 0, err
+	}
+
+	count := removeFn(zs)
+	if count > 0 {
+		store.keyModified(key)
+		store.notifyRemoval(pubsub.NotifyZSet, string(action), key, zs.Len() == 0)
+	}
+	store.deleteSortedSetIfEmpty(key, zs)
+
+	return count, nil
+}
+
+func (store *Store) ZRemRangeByRank(key string, start, stop int) (int, error) {
+	return store.zremRange(key, actions.ZRemRangeByRank, func(zs *objects.SortedSet) int {
+		return zs.RemoveRangeByRank(start, stop)
+	})
+}
+
+func (store *Store) ZRemRangeByScore(key string, r objects.ScoreRange) (int, error) {
+	return store.zremRange(key, actions.ZRemRangeByScore, func(zs *objects.SortedSet) int {
+		return zs.RemoveRangeByScore(r)
+	})
+}
+
+func (store *Store) ZRemRangeByLex(key string, r objects.LexRange) (int, error) {
+	return store.zremRange(key, actions.ZRemRangeByLex, func(zs *objects.SortedSet) int {
+		return zs.RemoveRangeByLex(r)
+	})
+}
+
+// ———————————————————————————————————————————————————————————————
+// Blocking sorted set pops
+// ———————————————————————————————————————————————————————————————
+
+// zpopEvent is the keyspace notification of a pop from the given end of a sorted set
+func zpopEvent(direction actions.BlockingPopDirection) string {
+	if direction == actions.BMAX {
+		return "zpopmax"
+	}
+	return "zpopmin"
+}
+
+func (store *Store) addZSetWaiter(key string, client *objects.ZSetBlockingPopClient) {
+	// must be used with a lock
+	store.zsetWaiters[key] = append(store.zsetWaiters[key], client)
+}
+
+// nextZSetWaiter takes the longest waiting client off the queue of the key and marks it served
+func (store *Store) nextZSetWaiter(key string) *objects.ZSetBlockingPopClient {
+	// must be used with a lock
+	store.pruneZSetWaiters([]string{key})
+
+	waiters := store.zsetWaiters[key]
+	if len(waiters) == 0 {
+		return nil
+	}
+
+	client := waiters[0]
+	client.Serve()
+
+	if len(waiters) == 1 {
+		delete(store.zsetWaiters, key)
+	} else {
+		store.zsetWaiters[key] = waiters[1:]
+	}
+
+	return client
+}
+
+// pruneZSetWaiters drops the clients that were served by another key or gave up waiting
+func (store *Store) pruneZSetWaiters(keys []string) {
+	// must be used with a lock
+	for _, key := range keys {
+		waiters := store.zsetWaiters[key]
+
+		live := waiters[:0]
+		for _, client := range waiters {
+			if !client.IsDone() {
+				live = append(live, client)
+			}
+		}
+
+		if len(live) == 0 {
+			delete(store.zsetWaiters, key)
+		} else {
+			store.zsetWaiters[key] = live
+		}
+	}
+}
+
+// serveZSetClients pops a member for every client blocked on the key in FIFO order
+// while the sorted set has members
+func (store *Store) serveZSetClients(key string, zs *objects.SortedSet) []objects.ZSetBlockingPopDispersal {
+	// must be used with a lock
+	dispersals := []objects.ZSetBlockingPopDispersal{}
+
+	for zs.Len() > 0 {
+		client := store.nextZSetWaiter(key)
+		if client == nil {
+			break
+		}
+
+		popped := zs.Pop(1, client.Direction() == actions.BMAX)
+		store.notifyRemoval(pubsub.NotifyZSet, zpopEvent(client.Direction()), key, zs.Len() == 0)
+
+		dispersals = append(dispersals, objects.ZSetBlockingPopDispersal{
+			Channel: client.Channel(),
+			Value:   objects.ZSetPopResult{Key: key, Member: popped[0], Logged: true},
+			Entry:   store.logEntry(zpopAction(client.Direction()), key, "1"),
+		})
+	}
+	store.deleteSortedSetIfEmpty(key, zs)
+
+	return dispersals
+}
+
+// disperseToZSetClients sends the served clients their members, the pops are logged
+// with the command the view runs
+func (store *Store) disperseToZSetClients(dispersals []objects.ZSetBlockingPopDispersal) {
+	for _, dispersal := range dispersals {
+		store.logServed(dispersal.Entry)
+		dispersal.Channel <- dispersal.Value
+	}
+}
+
+func zpopAction(direction actions.BlockingPopDirection) actions.Action {
+	if direction == actions.BMAX {
+		return actions.ZPopMax
+	}
+	return actions.ZPopMin
+}
+
+// blockingZPop pops from the first sorted set among keys, or parks the client on all
+// of them until a member arrives. A zero timeout waits forever.
+func (store *Store) blockingZPop(ctx context.Context, keys []string, direction actions.BlockingPopDirection, timeout time.Duration) (objects.ZSetPopResult, error) {
+	if direction != actions.BMIN && direction != actions.BMAX {
+		return objects.ZSetPopResult{}, errors.New("INVALID POP")
+	}
+
+	action := actions.BZPopMin
+	if direction == actions.BMAX {
+		action = actions.BZPopMax
+	}
+
+	store.mu.Lock()
+
+	for _, key := range keys {
+		zs, err := store.getSortedSet(key, action)
+		if err == errs.ErrNotFound {
+			continue
+		}
+
+		if err != nil {
+			store.mu.Unlock()
+			return objects.ZSetPopResult{}, err
+		}
+
+		popped := zs.Pop(1, direction == actions.BMAX)
+		store.keyModified(key)
+		store.notifyRemoval(pubsub.NotifyZSet, zpopEvent(direction), key, zs.Len() == 0)
+		store.deleteSortedSetIfEmpty(key, zs)
+		store.mu.Unlock()
+
+		return objects.ZSetPopResult{Key: key, Member: popped[0]}, nil
+	}
+
+	// none of the keys exist, the client waits outside the keyspace
+	client := objects.NewZSetBlockingPopClient(direction)
+	for _, key := range keys {
+		store.addZSetWaiter(key, client)
+	}
+	store.mu.Unlock()
+
+	var timeoutChannel <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		timeoutChannel = timer.C
+	}
+
+	select {
+	case result := <-client.Channel():
+		// drop the client from the other keys it was parked on
+		store.mu.Lock()
+		store.pruneZSetWaiters(keys)
+		store.mu.Unlock()
+
+		return store.keepZSetResult(result, action, context.Cause(ctx))
+
+	case <-timeoutChannel:
+		return store.abandonZSetClient(client, keys, action, errs.Timeout)
+
+	case <-ctx.Done():
+		return store.abandonZSetClient(client, keys, action, context.Cause(ctx))
+	}
+}
+
+// abandonZSetClient stops a client from waiting. A dispersal may have raced the cancellation,
+// the client then keeps the member it was served.
+func (store *Store) abandonZSetClient(client *objects.ZSetBlockingPopClient, keys []string, action actions.Action, cause error) (objects.ZSetPopResult, error) {
+	store.mu.Lock()
+
+	if !client.IsDone() {
+		client.Cancel()
+		store.pruneZSetWaiters(keys)
+		store.mu.Unlock()
+
+		return objects.ZSetPopResult{}, cause
+	}
+	store.mu.Unlock()
+
+	// sent right after the store lock is released by whoever served the client
+	return store.keepZSetResult(<-client.Channel(), action, cause)
+}
+
+// keepZSetResult hands a served client its member, unless its connection is gone, in which
+// case the member goes back to the sorted set so that it is not lost
+func (store *Store) keepZSetResult(result objects.ZSetPopResult, action actions.Action, cause error) (objects.ZSetPopResult, error) {
+	if cause != errs.ConnectionClosed {
+		return result, nil
+	}
+
+	store.mu.Lock()
+	var dispersals []objects.ZSetBlockingPopDispersal
+	if zs, err := store.getOrCreateSortedSet(result.Key, action); err == nil {
+		// the member may have been added again in the meantime, its new score wins
+		if _, exists := zs.Score(result.Member.Member); !exists {
+			zs.Add(result.Member.Member, result.Member.Score)
+			store.keyModified(result.Key)
+			store.notify(pubsub.NotifyZSet, "zadd", result.Key)
+
+			// the pop was logged by the command that served the client
+			score := strconv.FormatFloat(result.Member.Score, 'f', -1, 64)
+			store.logServed(store.logEntry(actions.ZAdd, result.Key, score, result.Member.Member))
+		}
+		dispersals = store.serveZSetClients(result.Key, zs)
+	}
+	store.mu.Unlock()
+
+	store.disperseToZSetClients(dispersals)
+
+	return objects.ZSetPopResult{}, cause
+}
+
+func (store *Store) BZPopMin(ctx context.Context, keys []string, timeout time.Duration) (objects.ZSetPopResult, error) {
+	return store.blockingZPop(ctx, keys, actions.BMIN, timeout)
+}
+
+func (store *Store) BZPopMax(ctx context.Context, keys []string, timeout time.Duration) (objects.ZSetPopResult, error) {
+	return store.blockingZPop(ctx, keys, actions.BMAX, timeout)
+}
@@ -2,22 +2,76 @@ package store
 
 import (
 	"errors"
+	"server/commands"
 	"server/errs"
 	"server/store/actions"
 	"server/store/cleanup"
 	"server/store/objects"
+	"server/store/pubsub"
 	"sync"
 	"time"
 )
 
+// Store is one numbered database, see NewDatabases
 type Store struct {
-	mu    sync.RWMutex
-	kvMap map[string]*objects.Object
+	*keyspace
+	mu sync.Locker // shared by all the databases
+
+	// the AOF entries of the clients the command running on the view served, see LogServedTo
+	served *[]*commands.RedisCommand
 }
 
-func NewStore() *Store {
+// keyspace is the data of a database, shared with the views EXEC runs its commands on
+type keyspace struct {
+	index int
+	kvMap *objects.Dict[*objects.Object]
+
+	// XREAD BLOCK clients waiting for entries, keyed by stream key
+	streamWaiters map[string][]chan struct{}
+
+	// BLPOP, BRPOP, BLMPOP and BLMOVE clients waiting for elements, keyed by list key.
+	// They wait on the key name, whatever it holds in the meantime.
+	listWaiters map[string][]*objects.BlockingPopClient
+
+	// BZPOPMIN and BZPOPMAX clients waiting for members, keyed by sorted set key
+	zsetWaiters map[string][]*objects.ZSetBlockingPopClient
+
+	// the WATCH states of the connections, keyed by watched key
+	watchers map[string]map[*Watch]struct{}
+
+	notifier *pubsub.Notifier // shared by all the databases
+}
+
+func newStore(index int, mu sync.Locker, notifier *pubsub.Notifier) *Store {
 	return &Store{
-		kvMap: make(map[string]*objects.Object),
+		keyspace: &keyspace{
+			index:         index,
+			kvMap:         objects.NewDict[*objects.Object](),
+			streamWaiters: make(map[string][]chan struct{}),
+			listWaiters:   make(map[string][]*objects.BlockingPopClient),
+			zsetWaiters:   make(map[string][]*objects.ZSetBlockingPopClient),
+			watchers:      make(map[string]map[*Watch]struct{}),
+			notifier:      notifier,
+		},
+		mu: mu,
+	}
+}
+
+// LogServedTo returns a view of the database for one command. The pops of the blocked
+// clients the command serves are added to served in the order they happen, so that the
+// AOF writes them right after the command instead of racing it from the served clients.
+func (store *Store) LogServedTo(served *[]*commands.RedisCommand) *Store {
+	return &Store{keyspace: store.keyspace, mu: store.mu, served: served}
+}
+
+// logEntry is a command written to the AOF on behalf of a blocked client
+func (store *Store) logEntry(action actions.Action, args ...string) *commands.RedisCommand {
+	return &commands.RedisCommand{Action: action, Arguments: args, DB: store.index}
+}
+
+func (store *Store) logServed(entry *commands.RedisCommand) {
+	if store.served != nil {
+		*store.served = append(*store.served, entry)
 	}
 }
 
@@ -30,7 +84,9 @@ func (store *Store) validateActionForDataType(object *objects.Object, action act
 	switch object.DataType {
 	case objects.String:
 		switch action {
-		case actions.Get, actions.Set:
+		case actions.Get, actions.Set, actions.Incr, actions.Decr, actions.IncrBy, actions.DecrBy, actions.IncrByFloat,
+			actions.Append, actions.StrLen, actions.GetRange, actions.SetRange,
+			actions.GetDel, actions.GetEx, actions.GetSet:
 			return object, nil
 		default:
 			return nil, errs.InvalidMethod
@@ -38,20 +94,50 @@ func (store *Store) validateActionForDataType(object *objects.Object, action act
 
 	case objects.List:
 		switch action {
-		case actions.LPush, actions.LPop, actions.RPush, actions.RPop:
+		case actions.LPush, actions.LPop, actions.RPush, actions.RPop,
+			actions.LRange, actions.LIndex, actions.LSet, actions.LInsert, actions.LRem, actions.LTrim, actions.LLen, actions.LPos,
+			actions.LMove, actions.BLMove, actions.RPopLPush, actions.BRPopLPush,
+			actions.BLPop, actions.BRPop, actions.LMPop, actions.BLMPop:
 			return object, nil
 		default:
 			return nil, errs.InvalidMethod
 		}
 	case objects.Hash:
 		switch action {
-		case actions.HGet, actions.HSet, actions.HGetAll, actions.HDel:
+		case actions.HGet, actions.HSet, actions.HGetAll, actions.HDel,
+			actions.HLen, actions.HKeys, actions.HVals, actions.HExists, actions.HSetNX, actions.HMGet,
+			actions.HIncrBy, actions.HIncrByFloat, actions.HStrLen, actions.HRandField,
+			actions.HExpire, actions.HPExpire, actions.HExpireAt, actions.HPExpireAt, actions.HTTL, actions.HPTTL, actions.HPersist,
+			actions.HScan:
 			return object, nil
 		default:
 			return nil, errs.InvalidMethod
 		}
 	case objects.Set:
-		return nil, errs.InvalidMethod
+		switch action {
+		case actions.SAdd, actions.SRem, actions.SMembers, actions.SIsMember, actions.SCard,
+			actions.SInter, actions.SUnion, actions.SDiff, actions.SInterStore, actions.SUnionStore, actions.SDiffStore, actions.SScan:
+			return object, nil
+		default:
+			return nil, errs.InvalidMethod
+		}
+	case objects.ZSet:
+		switch action {
+		case actions.ZAdd, actions.ZRange, actions.ZRank, actions.ZRevRank, actions.ZScore, actions.ZIncrBy, actions.ZRem, actions.ZCard,
+			actions.ZPopMin, actions.ZPopMax, actions.BZPopMin, actions.BZPopMax,
+			actions.ZRemRangeByScore, actions.ZRemRangeByRank, actions.ZRemRangeByLex, actions.ZScan:
+			return object, nil
+		default:
+			return nil, errs.InvalidMethod
+		}
+	case objects.Stream:
+		switch action {
+		case actions.XAdd, actions.XRange, actions.XRevRange, actions.XLen, actions.XDel, actions.XTrim, actions.XRead,
+			actions.XGroup, actions.XReadGroup, actions.XAck, actions.XPending, actions.XClaim, actions.XAutoClaim:
+			return object, nil
+		default:
+			return nil, errs.InvalidMethod
+		}
 	default:
 		return nil, errs.InvalidDataType
 	}
@@ -59,19 +145,28 @@ func (store *Store) validateActionForDataType(object *objects.Object, action act
 
 func (store *Store) getObject(key string) (*objects.Object, bool) {
 	// must be used with a lock
-	object, ok := store.kvMap[key]
+	object, ok := store.kvMap.Get(key)
 	if !ok {
 		return nil, false
 	}
 
 	if object.HasExpired() {
-		delete(store.kvMap, key)
+		store.deleteExpired(key)
 		return nil, false
 	}
 
 	return object, true
 }
 
+// deleteExpired deletes a key that reached its TTL, whether it is looked up or
+// found by the cleanup
+func (store *Store) deleteExpired(key string) {
+	// must be used with a lock
+	store.kvMap.Delete(key)
+	store.keyModified(key)
+	store.notify(pubsub.NotifyExpired, "expired", key)
+}
+
 func (store *Store) Get(key string) (string, error) {
 	store.mu.Lock()
 	defer store.mu.Unlock()
@@ -89,11 +184,62 @@ func (store *Store) Get(key string) (string, error) {
 	return value.Data.(string), nil
 }
 
-func (store *Store) Set(key string, value string) {
+type SetOptions struct {
+	NX       bool       // only set when the key does not exist
+	XX       bool       // only set when the key exists
+	KeepTTL  bool       // retain the TTL of the existing key
+	Get      bool       // return the previous string value
+	ExpireAt *time.Time // expire the key at this time
+}
+
+type SetResult struct {
+	Previous string // previous value, only read when Get is set
+	Existed  bool
+	Written  bool
+}
+
+func (store *Store) Set(key string, value string, options SetOptions) (SetResult, error) {
 	store.mu.Lock()
 	defer store.mu.Unlock()
 
-	store.kvMap[key] = objects.NewObject(objects.String, value)
+	result := SetResult{}
+
+	previous, exists := store.getObject(key)
+	if exists {
+		result.Existed = true
+
+		if options.Get {
+			if previous.DataType != objects.String {
+				return result, errs.InvalidMethod
+			}
+			result.Previous = previous.Data.(string)
+		}
+	}
+
+	if (options.NX && exists) || (options.XX && !exists) {
+		return result, nil
+	}
+
+	object := objects.NewObject(objects.String, value)
+
+	if options.KeepTTL && exists && previous.GetExpiry() != nil {
+		object.ExpireAt(previous.GetExpiry().At)
+	}
+
+	if options.ExpireAt != nil {
+		object.ExpireAt(*options.ExpireAt)
+	}
+
+	// the expiry is queued along with the object, a string serves no blocked client
+	store.putObject(key, object)
+	store.notify(pubsub.NotifyString, "set", key)
+
+	if options.ExpireAt != nil {
+		store.notify(pubsub.NotifyGeneric, "expire", key)
+	}
+
+	result.Written = true
+	return result, nil
 }
 
 func (store *Store) Delete(keys []string) int {
@@ -106,7 +252,9 @@ func (store *Store) Delete(keys []string) int {
 		_, exists := store.getObject(key)
 		if exists {
 			count++
-			delete(store.kvMap, key)
+			store.kvMap.Delete(key)
+			store.keyModified(key)
+			store.notify(pubsub.NotifyGeneric, "del", key)
 		}
 	}
 
@@ -117,6 +265,12 @@ func (store *Store) Exists(keys []string) int {
 	store.mu.Lock()
 	defer store.mu.Unlock()
 
+	return store.countExisting(keys)
+}
+
+// countExisting counts the keys that exist, a key given twice is counted twice
+func (store *Store) countExisting(keys []string) int {
+	// must be used with a lock
 	count := 0
 
 	for _, key := range keys {
@@ -128,38 +282,107 @@ func (store *Store) Exists(keys []string) int {
 	return count
 }
 
-func (store *Store) Expire(key string, seconds int64) error {
+type ExpireCondition string
+
+const (
+	ExpireAlways ExpireCondition = ""
+	ExpireNX     ExpireCondition = "NX" // only when the key has no expiry
+	ExpireXX     ExpireCondition = "XX" // only when the key has an expiry
+	ExpireGT     ExpireCondition = "GT" // only when the new expiry is later, no expiry counts as infinite
+	ExpireLT     ExpireCondition = "LT" // only when the new expiry is earlier
+)
+
+// allows reports whether an expiry can move from current to at, hasExpiry is false when there is none
+func (condition ExpireCondition) allows(current time.Time, hasExpiry bool, at time.Time) bool {
+	switch condition {
+	case ExpireNX:
+		return !hasExpiry
+	case ExpireXX:
+		return hasExpiry
+	case ExpireGT:
+		return hasExpiry && at.UnixMilli() > current.UnixMilli()
+	case ExpireLT:
+		return !hasExpiry || at.UnixMilli() < current.UnixMilli()
+	default:
+		return true
+	}
+}
+
+// ExpireAt sets the expiry of a key if the condition holds, an expiry in the past deletes the key
+func (store *Store) ExpireAt(key string, at time.Time, condition ExpireCondition) (bool, error) {
 	store.mu.Lock()
 	defer store.mu.Unlock()
 
 	object, exists := store.getObject(key)
 	if !exists {
-		return errs.ErrNotFound
+		return false, errs.ErrNotFound
+	}
+
+	current, hasExpiry := time.Time{}, false
+	if expiry := object.GetExpiry(); expiry != nil {
+		current, hasExpiry = expiry.At, true
+	}
+
+	if !condition.allows(current, hasExpiry, at) {
+		return false, nil
 	}
 
-	object.Expire(seconds)
-	pq := cleanup.GetPQ()
+	if !time.Now().Before(at) {
+		store.kvMap.Delete(key)
+		store.keyModified(key)
+		store.notify(pubsub.NotifyGeneric, "del", key)
+		return true, nil
+	}
 
-	pq.HPush(key, object.GetExpiry().At)
+	store.setExpiry(key, object, at)
+	store.keyModified(key)
+	store.notify(pubsub.NotifyGeneric, "expire", key)
 
-	return nil
+	return true, nil
 }
 
-func (store *Store) GetExpiry(key string) (time.Time, error) {
+// Persist removes the expiry of a key, reporting whether there was one
+func (store *Store) Persist(key string) bool {
 	store.mu.Lock()
 	defer store.mu.Unlock()
 
 	object, exists := store.getObject(key)
+	if !exists || object.GetExpiry() == nil {
+		return false
+	}
+
+	// the entry left in the cleanup queue no longer matches and is skipped
+	object.Persist()
+	store.keyModified(key)
+	store.notify(pubsub.NotifyGeneric, "persist", key)
+
+	return true
+}
+
+func (store *Store) setExpiry(key string, object *objects.Object, at time.Time) {
+	// must be used with a lock
+	object.ExpireAt(at)
+	cleanup.GetPQ().HPush(store.index, key, at)
+}
+
+// ExpireKey is used by the cleanup to delete a key once its queued expiry passed, nothing
+// happens when the key was rewritten, persisted or already expired lazily, or when the
+// expiry is still to come
+func (store *Store) ExpireKey(key string, at time.Time) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	object, exists := store.kvMap.Get(key)
 	if !exists {
-		return time.Time{}, errs.ErrNotFound
+		return
 	}
 
 	expiry := object.GetExpiry()
-	if expiry == nil {
-		return time.Time{}, errors.New("No expiry on element")
+	if expiry == nil || !expiry.At.Equal(at) || time.Now().Before(at) {
+		return
 	}
 
-	return expiry.At, nil
+	store.deleteExpired(key)
 }
 
 func (store *Store) TTL(key string) int {
@@ -175,10 +398,41 @@ func (store *Store) TTL(key string) int {
 	return object.TTL()
 }
 
-type listPushFn func(list *objects.RedisList, items []string) []objects.BlockingPopDisperal
+func (store *Store) PTTL(key string) int64 {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	object, exists := store.getObject(key)
+	if !exists {
+		return -2
+	}
+
+	return object.PTTL()
+}
+
+// ExpireTime returns the absolute expiry as a unix time in milliseconds,
+// -1 when the key does not expire and -2 when it does not exist
+func (store *Store) ExpireTime(key string) int64 {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	object, exists := store.getObject(key)
+	if !exists {
+		return -2
+	}
+
+	expiry := object.GetExpiry()
+	if expiry == nil {
+		return -1
+	}
+
+	return expiry.At.UnixMilli()
+}
+
+type listPushFn func(list *objects.RedisList, items []string)
 type listPopFn func(list *objects.RedisList, count int) []string
 
-func (store *Store) push(key string, items []string, pushFn listPushFn) ([]objects.BlockingPopDisperal, int, error) {
+func (store *Store) push(key string, items []string, event string, pushFn listPushFn) ([]objects.BlockingPopDisperal, int, error) {
 	store.mu.Lock()
 	defer store.mu.Unlock()
 
@@ -187,43 +441,49 @@ func (store *Store) push(key string, items []string, pushFn listPushFn) ([]objec
 	// make a new list
 	if !exists {
 		redisList := objects.NewList()
-		store.kvMap[key] = objects.NewObject(objects.List, redisList)		// place reference
-		result := pushFn(redisList, items)
-		return result, redisList.GetSize(), nil
+		store.kvMap.Set(key, objects.NewObject(objects.List, redisList))		// place reference
+		pushFn(redisList, items)
+		newSize := redisList.GetSize()
+		store.keyModified(key)
+		store.notify(pubsub.NotifyList, event, key)
+
+		// the clients blocked on the missing key are served from the new list
+		return store.serveListClients(key, redisList), newSize, nil
 	}
 
 	object, err := store.validateActionForDataType(object, actions.LPush)		// same for LPush or RPush (use either)
 
 	if err != nil {
-		store.mu.Unlock()
 		return nil, 0, err
 	}
 
 	redisList, ok := object.Data.(*objects.RedisList);
 
 	if !ok {
-		store.mu.Unlock()
 		return nil, 0, errors.New("TYPE MISMATCH")
 	} 
 
-	result := pushFn(redisList, items)
-	return result, redisList.GetSize(), nil
+	pushFn(redisList, items)
+	newSize := redisList.GetSize()
+	store.keyModified(key)
+	store.notify(pubsub.NotifyList, event, key)
+
+	// blocked clients are served after the whole push, the reply carries the length before that
+	return store.serveListClients(key, redisList), newSize, nil
 }
 
-func (store *Store) pushWithDispersal(key string, items []string, pushFn listPushFn) (int, error) {
-	dispersals, newSize, err := store.push(key, items, pushFn)
+func (store *Store) pushWithDispersal(key string, items []string, event string, pushFn listPushFn) (int, error) {
+	dispersals, newSize, err := store.push(key, items, event, pushFn)
 	if err != nil {
 		return 0, err
 	}
 
-	for _, dispersal := range dispersals {
-		dispersal.Channel <- dispersal.Value
-	}
+	store.disperseToListClients(dispersals)
 
 	return newSize, nil
 }
 
-func (store *Store) pop(key string, count int, popFn listPopFn) ([]string, error) {
+func (store *Store) pop(key string, count int, event string, popFn listPopFn) ([]string, error) {
 	store.mu.Lock()
 	defer store.mu.Unlock()
 	
@@ -242,14 +502,12 @@ func (store *Store) pop(key string, count int, popFn listPopFn) ([]string, error
 	if redisList, ok := object.Data.(*objects.RedisList); !ok {
 		return nil, errors.New("TYPE MISMATCH")
 	} else {
-		if redisList.GetSize() == 0 {
-			return nil, errors.New("CANNOT POP AN EMPTY LIST")
-		}
-
 		items := popFn(redisList, count)
+		store.keyModified(key)
+		store.notifyRemoval(pubsub.NotifyList, event, key, redisList.IsEmpty())
 
 		if redisList.IsEmpty() {
-			delete(store.kvMap, key)
+			store.kvMap.Delete(key)
 		}
 		
 		return items, nil
@@ -257,19 +515,19 @@ func (store *Store) pop(key string, count int, popFn listPopFn) ([]string, error
 }
 
 func (store *Store) LPush(key string, items []string) (int, error) {
-	return store.pushWithDispersal(key, items, func(list *objects.RedisList, items []string) []objects.BlockingPopDisperal {
-		return list.LPush(items)
+	return store.pushWithDispersal(key, items, "lpush", func(list *objects.RedisList, items []string) {
+		list.LPush(items)
 	})
 }
 
 func (store *Store) RPush(key string, items []string) (int, error) {
-	return store.pushWithDispersal(key, items, func(list *objects.RedisList, items []string) []objects.BlockingPopDisperal {
-		return list.RPush(items)
+	return store.pushWithDispersal(key, items, "rpush", func(list *objects.RedisList, items []string) {
+		list.RPush(items)
 	})
 }
 
 func (store *Store) LPop(key string, count int) ([]string, error) {
-	return store.pop(key, count, func(list *objects.RedisList, count int) []string {
+	return store.pop(key, count, "lpop", func(list *objects.RedisList, count int) []string {
 		popCount := min(count, list.GetSize())
 		items := make([]string, min(count, popCount))
 
@@ -283,7 +541,7 @@ func (store *Store) LPop(key string, count int) ([]string, error) {
 }
 
 func (store *Store) RPop(key string, count int) ([]string, error) {
-	return store.pop(key, count, func(list *objects.RedisList, count int) []string {
+	return store.pop(key, count, "rpop", func(list *objects.RedisList, count int) []string {
 		popCount := min(count, list.GetSize())
 		items := make([]string, min(count, popCount))
 
@@ -296,73 +554,6 @@ func (store *Store) RPop(key string, count int) ([]string, error) {
 	})
 }
 
-func (store *Store) blockingPop(key string, direction actions.BlockingPopDirection) (string, error) {
-	if direction != actions.BLEFT && direction != actions.BRIGHT {
-		return "", errors.New("INVALID POP")
-	}
-
-	var redisList *objects.RedisList = nil
-
-	store.mu.Lock()
-	object, exists := store.getObject(key)
-
-	if !exists {
-		// acquiring lock for writing
-
-		object := objects.NewList()
-		store.kvMap[key] = objects.NewObject(objects.List, object)		// place reference
-		redisList = object
-
-		store.mu.Unlock()
-	} else {
-		store.mu.Unlock()
-		object, err := store.validateActionForDataType(object, actions.LPop)
-		if err != nil {
-			return "", err
-		}
-
-		if redisListFromData, ok := object.Data.(*objects.RedisList); !ok {
-			return "", errors.New("SERVER TYPE MISMATCH")
-		} else {
-			redisList = redisListFromData
-		}
-	}
-
-	// acquiring lock when touching list
-	store.mu.Lock()
-	isEmpty := redisList.IsEmpty()
-	if !isEmpty {
-		if direction == "right"	{
-			item := redisList.LPop() 
-			store.mu.Unlock()
-			return item , nil
-		} else {
-			item := redisList.RPop()
-			store.mu.Unlock()
-			return item, nil
-		}
-	} else {
-		// redis list is empty
-		// make a channel, and add a blocking client			
-
-		channel := make(chan string)
-		redisList.AddBlockingPopClient(channel, direction)
-		store.mu.Unlock()		// releasing as we now let the redis list handling blocking pop upon a push
-
-		item := <-channel
-
-		return item, nil
-	}
-}
-
-func (store *Store) BLPop(key string) (string, error) {
-	return store.blockingPop(key, actions.BLEFT)
-}
-
-func (store *Store) BRPop(key string) (string, error) {
-	return store.blockingPop(key, actions.BRIGHT)
-}
-
 // ———————————————————————————————————————————————————————————————
 // Hash set methods
 // ———————————————————————————————————————————————————————————————
@@ -370,18 +561,8 @@ func (store *Store) BRPop(key string) (string, error) {
 func (store *Store) HGet(key, field string) (string, error) {
 	store.mu.Lock()
 	defer store.mu.Unlock()
-	object, exists := store.getObject(key)
-	if !exists {
-		return "", errs.ErrNotFound
-	}
-
-	object, err := store.validateActionForDataType(object, actions.HGet)
-
-	if err != nil {
-		return "", err
-	}
 
-	hs, err := objects.ValidateObjectAsHash(object)
+	hs, err := store.getHash(key, actions.HGet)
 	if err != nil {
 		return "", err
 	}
@@ -399,27 +580,15 @@ func (store *Store) HGetAll(key string) ([]string, error) {
 	store.mu.Lock()
 	defer store.mu.Unlock()
 
-	object, exists := store.getObject(key)
-
-	if !exists {
-		return nil, errs.ErrNotFound
-	}
-
-	object, err := store.validateActionForDataType(object, actions.HGet)
+	hs, err := store.getHash(key, actions.HGetAll)
 
 	if err != nil {
 		return nil, err
 	}
 
-	hs, err := objects.ValidateObjectAsHash(object)
+	response := make([]string, 0, 2 * hs.Len())
 
-	if err != nil {
-		return nil, err
-	}
-
-	response := make([]string, 0, 2 * len(hs))
-
-	for k, v := range hs {
+	for k, v := range hs.All() {
 		response = append(response, k)
 		response = append(response, v)
 	}
@@ -435,20 +604,7 @@ func (store *Store) HSet(key string, fieldValueArray []string) (int, error) {
 		return 0, errs.IncorrectNumberOfArguments
 	}
 
-	object, exists := store.getObject(key)
-	
-	if !exists {
-		object = objects.NewObject(objects.Hash, objects.NewHashSet())
-		store.kvMap[key] = object
-	}
-
-	object, err := store.validateActionForDataType(object, actions.HGet)
-
-	if err != nil {
-		return 0, err
-	}
-
-	hs, err := objects.ValidateObjectAsHash(object)
+	hs, err := store.getOrCreateHash(key, actions.HSet)
 
 	if err != nil {
 		return 0, err
@@ -465,6 +621,8 @@ func (store *Store) HSet(key string, fieldValueArray []string) (int, error) {
 
 		hs.Set(field, value)
 	}
+	store.keyModified(key)
+	store.notify(pubsub.NotifyHash, "hset", key)
 
 	return count, nil
 }
@@ -473,23 +631,18 @@ func (store *Store) HDel(key string, fields []string) (int, error) {
 	store.mu.Lock()
 	defer store.mu.Unlock()
 
-	object, exists := store.getObject(key)
-
-	if !exists {
-		return 0, errs.ErrNotFound
-	}
-
-	object, err := store.validateActionForDataType(object, actions.HGet)
+	hs, err := store.getHash(key, actions.HDel)
 
 	if err != nil {
 		return 0, err
 	}
 
-	hs, err := objects.ValidateObjectAsHash(object)
-
-	if err != nil {
-		return 0, err
+	count := hs.Delete(fields)
+	if count > 0 {
+		store.keyModified(key)
+		store.notifyRemoval(pubsub.NotifyHash, "hdel", key, hs.Len() == 0)
 	}
+	store.deleteHashIfEmpty(key, hs)
 
-	return hs.Delete(fields), nil
+	return count, nil
 }
\ No newline at end of file
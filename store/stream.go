@@ -0,0 +1,286 @@
+package store
+
+import (
+	"context"
+	"server/errs"
+	"server/store/actions"
+	"server/store/objects"
+	"server/store/pubsub"
+	"time"
+)
+
+// ———————————————————————————————————————————————————————————————
+// Stream methods
+// ———————————————————————————————————————————————————————————————
+
+type StreamReadResult struct {
+	Key     string
+	Entries []objects.StreamEntry
+}
+
+func (store *Store) getStream(key string, action actions.Action) (*objects.RedisStream, error) {
+	// must be used with a lock
+	object, exists := store.getObject(key)
+	if !exists {
+		return nil, errs.ErrNotFound
+	}
+
+	object, err := store.validateActionForDataType(object, action)
+	if err != nil {
+		return nil, err
+	}
+
+	return objects.ValidateObjectAsStream(object)
+}
+
+// XAdd appends an entry and applies the optional trim, the stream is only created
+// once the ID has been accepted
+func (store *Store) XAdd(key string, idSpec string, fields []string, noMkStream bool, trim *objects.StreamTrim) (objects.StreamID, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	rs, err := store.getStream(key, actions.XAdd)
+	created := false
+
+	if err == errs.ErrNotFound {
+		if noMkStream {
+			return objects.StreamID{}, errs.ErrNotFound
+		}
+
+		rs = objects.NewRedisStream()
+		created = true
+	} else if err != nil {
+		return objects.StreamID{}, err
+	}
+
+	id, err := rs.Add(idSpec, fields)
+	if err != nil {
+		return id, err
+	}
+
+	if created {
+		store.kvMap.Set(key, objects.NewObject(objects.Stream, rs))
+	}
+
+	store.keyModified(key)
+	store.notify(pubsub.NotifyStream, "xadd", key)
+
+	if trim != nil && rs.Trim(*trim) > 0 {
+		store.notify(pubsub.NotifyStream, "xtrim", key)
+	}
+
+	store.signalStreamWaiters(key)
+
+	return id, nil
+}
+
+func (store *Store) XRange(key string, start, end objects.StreamID, count int, reverse bool) ([]objects.StreamEntry, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	action := actions.XRange
+	if reverse {
+		action = actions.XRevRange
+	}
+
+	rs, err := store.getStream(key, action)
+	if err != nil {
+		return nil, err
+	}
+
+	return rs.Range(start, end, count, reverse), nil
+}
+
+func (store *Store) XLen(key string) (int, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	rs, err := store.getStream(key, actions.XLen)
+	if err != nil {
+		return 0, err
+	}
+
+	return rs.Len(), nil
+}
+
+func (store *Store) XDel(key string, ids []objects.StreamID) (int, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	rs, err := store.getStream(key, actions.XDel)
+	if err != nil {
+		return 0, err
+	}
+
+	// unlike other types, an emptied stream is kept along with its last ID
+	deleted := rs.Delete(ids)
+	if deleted > 0 {
+		store.keyModified(key)
+		store.notify(pubsub.NotifyStream, "xdel", key)
+	}
+
+	return deleted, nil
+}
+
+func (store *Store) XTrim(key string, trim objects.StreamTrim) (int, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	rs, err := store.getStream(key, actions.XTrim)
+	if err != nil {
+		return 0, err
+	}
+
+	trimmed := rs.Trim(trim)
+	if trimmed > 0 {
+		store.keyModified(key)
+		store.notify(pubsub.NotifyStream, "xtrim", key)
+	}
+
+	return trimmed, nil
+}
+
+// XRead returns the entries after the given IDs ("$" meaning the current last ID).
+// When block is set and nothing is available it waits for an XADD to one of the keys,
+// a zero timeout waits forever.
+func (store *Store) XRead(ctx context.Context, keys []string, ids []string, count int, block bool, timeout time.Duration) ([]StreamReadResult, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	after := make([]objects.StreamID, len(keys))
+	for i, key := range keys {
+		if ids[i] != "$" {
+			id, err := objects.ParseStreamID(ids[i], 0)
+			if err != nil {
+				return nil, err
+			}
+			after[i] = id
+			continue
+		}
+
+		rs, err := store.getStream(key, actions.XRead)
+		if err == errs.ErrNotFound {
+			after[i] = objects.MinStreamID
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+		after[i] = rs.LastID()
+	}
+
+	readFn := func() ([]StreamReadResult, error) {
+		return store.readStreams(keys, after, count)
+	}
+
+	results, err := readFn()
+	if err != nil || len(results) > 0 || !block {
+		return results, err
+	}
+
+	return store.blockOnStreams(ctx, keys, timeout, readFn)
+}
+
+type streamReadFn func() ([]StreamReadResult, error)
+
+// blockOnStreams parks the client until an XADD to one of the keys makes readFn return
+// something, the timeout elapses or ctx is done. A zero timeout waits forever.
+func (store *Store) blockOnStreams(ctx context.Context, keys []string, timeout time.Duration, readFn streamReadFn) ([]StreamReadResult, error) {
+	// must be used with a lock, which is released while parked
+	var timeoutChannel <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		timeoutChannel = timer.C
+	}
+
+	for {
+		waiter := make(chan struct{}, 1)
+		for _, key := range keys {
+			store.streamWaiters[key] = append(store.streamWaiters[key], waiter)
+		}
+
+		// releasing while parked, XADD signals the waiter under the lock
+		store.mu.Unlock()
+
+		timedOut := false
+		select {
+		case <-waiter:
+		case <-timeoutChannel:
+			timedOut = true
+		case <-ctx.Done():
+			// nothing is read for a client that was unblocked or went away
+			store.mu.Lock()
+			store.removeStreamWaiter(keys, waiter)
+			return nil, context.Cause(ctx)
+		}
+
+		store.mu.Lock()
+		store.removeStreamWaiter(keys, waiter)
+
+		results, err := readFn()
+		if err != nil || len(results) > 0 {
+			return results, err
+		}
+
+		if timedOut {
+			return nil, errs.Timeout
+		}
+	}
+}
+
+func (store *Store) readStreams(keys []string, after []objects.StreamID, count int) ([]StreamReadResult, error) {
+	// must be used with a lock
+	results := []StreamReadResult{}
+
+	for i, key := range keys {
+		rs, err := store.getStream(key, actions.XRead)
+		if err == errs.ErrNotFound {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		entries := rs.After(after[i], count)
+		if len(entries) > 0 {
+			results = append(results, StreamReadResult{Key: key, Entries: entries})
+		}
+	}
+
+	return results, nil
+}
+
+func (store *Store) signalStreamWaiters(key string) {
+	// must be used with a lock
+	for _, waiter := range store.streamWaiters[key] {
+		select {
+		case waiter <- struct{}{}:
+		default:
+		}
+	}
+
+	delete(store.streamWaiters, key)
+}
+
+func (store *Store) removeStreamWaiter(keys []string, waiter chan struct{}) {
+	// must be used with a lock
+	for _, key := range keys {
+		waiters := store.streamWaiters[key]
+
+		live := waiters[:0]
+		for _, w := range waiters {
+			if w != waiter {
+				live = append(live, w)
+			}
+		}
+
+		if len(live) == 0 {
+			delete(store.streamWaiters, key)
+		} else {
+			store.streamWaiters[key] = live
+		}
+	}
+}
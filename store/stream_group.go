@@ -0,0 +1,290 @@
+package store
+
+import (
+	"context"
+	"server/errs"
+	"server/store/actions"
+	"server/store/objects"
+	"server/store/pubsub"
+	"time"
+)
+
+// ———————————————————————————————————————————————————————————————
+// Stream consumer group methods
+// ———————————————————————————————————————————————————————————————
+
+func (store *Store) getStreamGroup(key, groupName string, action actions.Action) (*objects.RedisStream, *objects.StreamGroup, error) {
+	// must be used with a lock
+	rs, err := store.getStream(key, action)
+	if err == errs.ErrNotFound {
+		return nil, nil, errs.NoGroup
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	group, err := rs.Group(groupName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return rs, group, nil
+}
+
+func resolveGroupID(rs *objects.RedisStream, idSpec string) (objects.StreamID, error) {
+	if idSpec == "$" {
+		return rs.LastID(), nil
+	}
+
+	return objects.ParseStreamID(idSpec, 0)
+}
+
+func (store *Store) XGroupCreate(key, groupName, idSpec string, mkStream bool) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	rs, err := store.getStream(key, actions.XGroup)
+	created := false
+
+	if err == errs.ErrNotFound {
+		if !mkStream {
+			return errs.XGroupKeyMissing
+		}
+
+		rs = objects.NewRedisStream()
+		created = true
+	} else if err != nil {
+		return err
+	}
+
+	id, err := resolveGroupID(rs, idSpec)
+	if err != nil {
+		return err
+	}
+
+	if err := rs.CreateGroup(groupName, id); err != nil {
+		return err
+	}
+
+	if created {
+		store.kvMap.Set(key, objects.NewObject(objects.Stream, rs))
+	}
+	store.keyModified(key)
+	store.notify(pubsub.NotifyStream, "xgroup-create", key)
+
+	return nil
+}
+
+func (store *Store) XGroupSetID(key, groupName, idSpec string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	rs, group, err := store.getStreamGroup(key, groupName, actions.XGroup)
+	if err != nil {
+		return err
+	}
+
+	id, err := resolveGroupID(rs, idSpec)
+	if err != nil {
+		return err
+	}
+
+	group.LastDeliveredID = id
+	store.keyModified(key)
+	store.notify(pubsub.NotifyStream, "xgroup-setid", key)
+	return nil
+}
+
+func (store *Store) XGroupDestroy(key, groupName string) (int, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	rs, err := store.getStream(key, actions.XGroup)
+	if err == errs.ErrNotFound {
+		return 0, errs.XGroupKeyMissing
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	if !rs.DestroyGroup(groupName) {
+		return 0, nil
+	}
+
+	// blocked XREADGROUP clients wake up to find their group gone
+	store.keyModified(key)
+	store.notify(pubsub.NotifyStream, "xgroup-destroy", key)
+	store.signalStreamWaiters(key)
+	return 1, nil
+}
+
+func (store *Store) XGroupCreateConsumer(key, groupName, consumer string) (int, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	_, group, err := store.getStreamGroup(key, groupName, actions.XGroup)
+	if err != nil {
+		return 0, err
+	}
+
+	if group.CreateConsumer(consumer) {
+		store.keyModified(key)
+		store.notify(pubsub.NotifyStream, "xgroup-createconsumer", key)
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func (store *Store) XGroupDelConsumer(key, groupName, consumer string) (int, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	_, group, err := store.getStreamGroup(key, groupName, actions.XGroup)
+	if err != nil {
+		return 0, err
+	}
+
+	pending := group.DeleteConsumer(consumer)
+	store.keyModified(key)
+	store.notify(pubsub.NotifyStream, "xgroup-delconsumer", key)
+
+	return pending, nil
+}
+
+// XReadGroup reads new entries (">") or the consumer's pending history (an explicit ID).
+// Only reads for new entries block.
+func (store *Store) XReadGroup(ctx context.Context, groupName, consumer string, keys, ids []string, count int, noAck, block bool, timeout time.Duration) ([]StreamReadResult, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	history := false
+	after := make([]objects.StreamID, len(keys))
+
+	for i, key := range keys {
+		if _, _, err := store.getStreamGroup(key, groupName, actions.XReadGroup); err != nil {
+			return nil, err
+		}
+
+		if ids[i] == ">" {
+			continue
+		}
+
+		id, err := objects.ParseStreamID(ids[i], 0)
+		if err != nil {
+			return nil, err
+		}
+
+		after[i] = id
+		history = true
+	}
+
+	readFn := func() ([]StreamReadResult, error) {
+		results := []StreamReadResult{}
+
+		for i, key := range keys {
+			rs, group, err := store.getStreamGroup(key, groupName, actions.XReadGroup)
+			if err != nil {
+				return nil, err
+			}
+
+			if ids[i] != ">" {
+				// history replies list every stream, even without entries
+				entries := group.ReadHistory(rs, consumer, after[i], count)
+				results = append(results, StreamReadResult{Key: key, Entries: entries})
+				continue
+			}
+
+			entries := group.ReadNew(rs, consumer, count, noAck)
+			if len(entries) > 0 {
+				results = append(results, StreamReadResult{Key: key, Entries: entries})
+			}
+		}
+
+		return results, nil
+	}
+
+	results, err := readFn()
+	if err != nil || len(results) > 0 || !block || history {
+		return results, err
+	}
+
+	return store.blockOnStreams(ctx, keys, timeout, readFn)
+}
+
+func (store *Store) XAck(key, groupName string, ids []objects.StreamID) (int, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	_, group, err := store.getStreamGroup(key, groupName, actions.XAck)
+	if err != nil {
+		return 0, err
+	}
+
+	acked := group.Ack(ids)
+	if acked > 0 {
+		store.keyModified(key)
+	}
+
+	return acked, nil
+}
+
+func (store *Store) XPendingSummary(key, groupName string) (objects.StreamPendingSummary, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	_, group, err := store.getStreamGroup(key, groupName, actions.XPending)
+	if err != nil {
+		return objects.StreamPendingSummary{}, err
+	}
+
+	return group.PendingSummary(), nil
+}
+
+func (store *Store) XPendingRange(key, groupName string, query objects.StreamPendingQuery) ([]objects.StreamPendingEntry, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	_, group, err := store.getStreamGroup(key, groupName, actions.XPending)
+	if err != nil {
+		return nil, err
+	}
+
+	return group.PendingRange(query), nil
+}
+
+// XClaim returns the claimed entries and the pending IDs dropped because their entry was deleted
+func (store *Store) XClaim(key, groupName, consumer string, minIdle time.Duration, ids []objects.StreamID, options objects.StreamClaimOptions) ([]objects.StreamEntry, []objects.StreamID, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	rs, group, err := store.getStreamGroup(key, groupName, actions.XClaim)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	claimed, deleted := group.Claim(rs, consumer, minIdle, ids, options)
+	if len(claimed) > 0 || len(deleted) > 0 {
+		store.keyModified(key)
+	}
+
+	return claimed, deleted, nil
+}
+
+func (store *Store) XAutoClaim(key, groupName, consumer string, minIdle time.Duration, start objects.StreamID, count int, justID bool) (objects.StreamID, []objects.StreamEntry, []objects.StreamID, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	rs, group, err := store.getStreamGroup(key, groupName, actions.XAutoClaim)
+	if err != nil {
+		return objects.StreamID{}, nil, nil, err
+	}
+
+	next, claimed, deleted := group.AutoClaim(rs, consumer, minIdle, start, count, justID)
+	if len(claimed) > 0 || len(deleted) > 0 {
+		store.keyModified(key)
+	}
+
+	return next, claimed, deleted, nil
+}
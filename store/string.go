@@ -0,0 +1,332 @@
+package store
+
+import (
+	"math"
+	"server/errs"
+	"server/store/actions"
+	"server/store/objects"
+	"server/store/pubsub"
+	"strconv"
+	"time"
+)
+
+// ———————————————————————————————————————————————————————————————
+// String methods
+// ———————————————————————————————————————————————————————————————
+
+const MAX_STRING_LENGTH = 512 * 1024 * 1024
+
+func (store *Store) getString(key string, action actions.Action) (*objects.Object, error) {
+	// must be used with a lock
+	object, exists := store.getObject(key)
+	if !exists {
+		return nil, errs.ErrNotFound
+	}
+
+	return store.validateActionForDataType(object, action)
+}
+
+// putString stores a new string object under key, discarding the TTL of the key
+func (store *Store) putString(key, value string) {
+	// must be used with a lock
+	// a string serves no blocked client
+	store.putObject(key, objects.NewObject(objects.String, value))
+	store.notify(pubsub.NotifyString, "set", key)
+}
+
+// setStringInPlace updates the value of an existing string object, keeping its TTL,
+// or creates a new object. event is the keyspace notification of the command.
+func (store *Store) setStringInPlace(key string, object *objects.Object, value string, event string) {
+	// must be used with a lock
+	store.keyModified(key)
+	store.notify(pubsub.NotifyString, event, key)
+
+	if object == nil {
+		store.kvMap.Set(key, objects.NewObject(objects.String, value))
+		return
+	}
+
+	object.Data = value
+}
+
+func (store *Store) IncrBy(key string, delta int64) (int64, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	object, err := store.getString(key, actions.IncrBy)
+	if err != nil && err != errs.ErrNotFound {
+		return 0, err
+	}
+
+	current := int64(0)
+	if object != nil {
+		current, err = strconv.ParseInt(object.Data.(string), 10, 64)
+		if err != nil {
+			return 0, errs.NotAnInteger
+		}
+	}
+
+	if (delta > 0 && current > math.MaxInt64-delta) || (delta < 0 && current < math.MinInt64-delta) {
+		return 0, errs.IncrOverflow
+	}
+
+	current += delta
+	store.setStringInPlace(key, object, strconv.FormatInt(current, 10), "incrby")
+
+	return current, nil
+}
+
+func (store *Store) IncrByFloat(key string, delta float64) (float64, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	object, err := store.getString(key, actions.IncrByFloat)
+	if err != nil && err != errs.ErrNotFound {
+		return 0, err
+	}
+
+	current := float64(0)
+	if object != nil {
+		current, err = strconv.ParseFloat(object.Data.(string), 64)
+		if err != nil || math.IsNaN(current) || math.IsInf(current, 0) {
+			return 0, errs.NotAFloat
+		}
+	}
+
+	current += delta
+	if math.IsNaN(current) || math.IsInf(current, 0) {
+		return 0, errs.IncrNaNOrInfinity
+	}
+
+	store.setStringInPlace(key, object, FormatFloat(current), "incrbyfloat")
+
+	return current, nil
+}
+
+func (store *Store) Append(key, value string) (int, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	object, err := store.getString(key, actions.Append)
+	if err != nil && err != errs.ErrNotFound {
+		return 0, err
+	}
+
+	current := ""
+	if object != nil {
+		current = object.Data.(string)
+	}
+
+	if len(current)+len(value) > MAX_STRING_LENGTH {
+		return 0, errs.StringTooLong
+	}
+
+	current += value
+	store.setStringInPlace(key, object, current, "append")
+
+	return len(current), nil
+}
+
+func (store *Store) StrLen(key string) (int, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	object, err := store.getString(key, actions.StrLen)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(object.Data.(string)), nil
+}
+
+// GetRange returns the bytes between the inclusive start and end offsets,
+// negative offsets count from the end
+func (store *Store) GetRange(key string, start, end int) (string, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	object, err := store.getString(key, actions.GetRange)
+	if err != nil {
+		return "", err
+	}
+
+	value := object.Data.(string)
+	length := len(value)
+
+	if start < 0 {
+		start += length
+	}
+	if end < 0 {
+		end += length
+	}
+	start = max(start, 0)
+	end = max(end, 0)
+	end = min(end, length-1)
+
+	if length == 0 || start > end {
+		return "", nil
+	}
+
+	return value[start : end+1], nil
+}
+
+// SetRange overwrites the value from offset on, zero padding when the string is shorter
+func (store *Store) SetRange(key string, offset int, value string) (int, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	object, err := store.getString(key, actions.SetRange)
+	if err != nil && err != errs.ErrNotFound {
+		return 0, err
+	}
+
+	current := ""
+	if object != nil {
+		current = object.Data.(string)
+	}
+
+	// nothing to write, a missing key is not created
+	if len(value) == 0 {
+		return len(current), nil
+	}
+
+	if offset+len(value) > MAX_STRING_LENGTH {
+		return 0, errs.StringTooLong
+	}
+
+	buf := []byte(current)
+	if len(buf) < offset+len(value) {
+		buf = append(buf, make([]byte, offset+len(value)-len(buf))...)
+	}
+	copy(buf[offset:], value)
+
+	store.setStringInPlace(key, object, string(buf), "setrange")
+
+	return len(buf), nil
+}
+
+// MGet returns the value of every key, nil for keys that are missing or do not hold a string
+func (store *Store) MGet(keys []string) []*string {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	values := make([]*string, len(keys))
+
+	for i, key := range keys {
+		object, exists := store.getObject(key)
+		if !exists || object.DataType != objects.String {
+			continue
+		}
+
+		value := object.Data.(string)
+		values[i] = &value
+	}
+
+	return values
+}
+
+// MSet sets every key value pair, like SET it discards existing TTLs
+func (store *Store) MSet(pairs []string) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	for i := 0; i < len(pairs); i += 2 {
+		store.putString(pairs[i], pairs[i+1])
+	}
+}
+
+// MSetNX sets the pairs only when none of the keys exist, all of them or nothing is written
+func (store *Store) MSetNX(pairs []string) bool {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	keys := make([]string, 0, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		keys = append(keys, pairs[i])
+	}
+
+	if store.countExisting(keys) > 0 {
+		return false
+	}
+
+	for i := 0; i < len(pairs); i += 2 {
+		store.putString(pairs[i], pairs[i+1])
+	}
+
+	return true
+}
+
+func (store *Store) GetDel(key string) (string, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	object, err := store.getString(key, actions.GetDel)
+	if err != nil {
+		return "", err
+	}
+
+	store.kvMap.Delete(key)
+	store.keyModified(key)
+	store.notify(pubsub.NotifyGeneric, "del", key)
+
+	return object.Data.(string), nil
+}
+
+type GetExOptions struct {
+	ExpireAt *time.Time // expire the key at this time
+	Persist  bool       // remove the TTL of the key
+}
+
+func (store *Store) GetEx(key string, options GetExOptions) (string, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	object, err := store.getString(key, actions.GetEx)
+	if err != nil {
+		return "", err
+	}
+
+	value := object.Data.(string)
+
+	switch {
+	case options.ExpireAt != nil && !time.Now().Before(*options.ExpireAt):
+		store.kvMap.Delete(key)
+		store.notify(pubsub.NotifyGeneric, "del", key)
+	case options.ExpireAt != nil:
+		store.setExpiry(key, object, *options.ExpireAt)
+		store.notify(pubsub.NotifyGeneric, "expire", key)
+	case options.Persist && object.GetExpiry() != nil:
+		object.Persist()
+		store.notify(pubsub.NotifyGeneric, "persist", key)
+	}
+
+	if options.ExpireAt != nil || options.Persist {
+		store.keyModified(key)
+	}
+
+	return value, nil
+}
+
+// GetSet replaces the value, dropping the TTL, and returns the previous one
+func (store *Store) GetSet(key, value string) (string, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	object, err := store.getString(key, actions.GetSet)
+	if err != nil && err != errs.ErrNotFound {
+		return "", err
+	}
+
+	store.putString(key, value)
+
+	if object == nil {
+		return "", errs.ErrNotFound
+	}
+
+	return object.Data.(string), nil
+}
+
+// FormatFloat renders floats the way INCRBYFLOAT replies, without exponent
+func FormatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,33 @@
+package store
+
+// ———————————————————————————————————————————————————————————————
+// Transactions
+// ———————————————————————————————————————————————————————————————
+
+// noLock is the lock of transaction views, the transaction holds the real one
+type noLock struct{}
+
+func (noLock) Lock()   {}
+func (noLock) Unlock() {}
+
+// Transaction locks every database and runs fn with views of them that do not lock,
+// so that the commands fn runs are not interleaved with the commands of other clients.
+// fn is not run when a key of the watch was modified, the returned bool reports whether
+// it ran. The keys are unwatched either way.
+func Transaction(databases []*Store, watch *Watch, fn func(views []*Store)) bool {
+	databases[0].mu.Lock()
+	defer databases[0].mu.Unlock()
+
+	defer unwatch(databases, watch)
+	if watch.modified(databases) {
+		return false
+	}
+
+	views := make([]*Store, len(databases))
+	for i, store := range databases {
+		views[i] = &Store{keyspace: store.keyspace, mu: noLock{}}
+	}
+
+	fn(views)
+	return true
+}
@@ -0,0 +1,96 @@
+package store
+
+// ———————————————————————————————————————————————————————————————
+// Watched keys
+// ———————————————————————————————————————————————————————————————
+
+// Watch is the WATCH state of a connection. It turns dirty once one of its keys is
+// modified, expired or deleted, EXEC then aborts the transaction.
+type Watch struct {
+	keys  []watchedKey
+	dirty bool
+}
+
+type watchedKey struct {
+	db  int
+	key string
+}
+
+func NewWatch() *Watch {
+	return &Watch{}
+}
+
+// keyModified is called by every mutation of a key, dirtying the watches on it
+func (store *Store) keyModified(key string) {
+	// must be used with a lock
+	for watch := range store.watchers[key] {
+		watch.dirty = true
+	}
+}
+
+// allKeysModified dirties the watches on every key that exists, used when the
+// whole keyspace is replaced
+func (store *Store) allKeysModified() {
+	// must be used with a lock
+	for key := range store.watchers {
+		if _, exists := store.kvMap.Get(key); exists {
+			store.keyModified(key)
+		}
+	}
+}
+
+// Watch adds the keys to the watch
+func (store *Store) Watch(watch *Watch, keys []string) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	for _, key := range keys {
+		if _, watching := store.watchers[key][watch]; watching {
+			continue
+		}
+
+		// a key that expired before WATCH is deleted now, not once EXEC looks it up
+		store.getObject(key)
+
+		if store.watchers[key] == nil {
+			store.watchers[key] = make(map[*Watch]struct{})
+		}
+		store.watchers[key][watch] = struct{}{}
+
+		watch.keys = append(watch.keys, watchedKey{db: store.index, key: key})
+	}
+}
+
+// Unwatch forgets every key of the watch
+func Unwatch(databases []*Store, watch *Watch) {
+	databases[0].mu.Lock()
+	defer databases[0].mu.Unlock()
+
+	unwatch(databases, watch)
+}
+
+func unwatch(databases []*Store, watch *Watch) {
+	// must be used with a lock
+	for _, watched := range watch.keys {
+		store := databases[watched.db]
+
+		delete(store.watchers[watched.key], watch)
+		if len(store.watchers[watched.key]) == 0 {
+			delete(store.watchers, watched.key)
+		}
+	}
+
+	watch.keys = nil
+	watch.dirty = false
+}
+
+// modified reports whether a watched key changed. Keys that expired since WATCH
+// were not deleted yet, looking them up deletes them.
+func (watch *Watch) modified(databases []*Store) bool {
+	// must be used with a lock
+	for _, watched := range watch.keys {
+		databases[watched.db].getObject(watched.key)
+	}
+
+	return watch.dirty
+}